@@ -0,0 +1,65 @@
+package arrow_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go"
+	charrow "github.com/ClickHouse/clickhouse-go/arrow"
+	"github.com/apache/arrow/go/v9/arrow"
+	"github.com/apache/arrow/go/v9/arrow/array"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Query(t *testing.T) {
+	ctx := context.Background()
+	if connect, err := sql.Open("clickhouse", "tcp://127.0.0.1:9000?debug=true"); assert.NoError(t, err) {
+		conn, err := connect.Conn(ctx)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		err = conn.Raw(func(driverConn interface{}) error {
+			reader, err := charrow.Query(ctx, driverConn.(clickhouse.Clickhouse), `
+				SELECT
+					number
+					, toString(number)
+					, if(number % 2 = 0, NULL, number)
+					, [number, number + 1]
+					, toDecimal64(number, 2)
+				FROM system.numbers LIMIT ?
+				SETTINGS max_block_size = 1000
+			`, 10000)
+			if err != nil {
+				return err
+			}
+			defer reader.Release()
+			schema := reader.Schema()
+			if assert.Len(t, schema.Fields(), 5) {
+				assert.Equal(t, arrow.PrimitiveTypes.Uint64, schema.Field(0).Type)
+				assert.Equal(t, arrow.BinaryTypes.String, schema.Field(1).Type)
+				assert.True(t, schema.Field(2).Nullable)
+				assert.Equal(t, arrow.ListOf(arrow.PrimitiveTypes.Uint64), schema.Field(3).Type)
+				assert.Equal(t, &arrow.Decimal128Type{Precision: 18, Scale: 2}, schema.Field(4).Type)
+			}
+			var count, sum, nulls int64
+			for reader.Next() {
+				record := reader.Record()
+				for _, n := range record.Column(0).(*array.Uint64).Uint64Values() {
+					sum += int64(n)
+				}
+				nulls += int64(record.Column(2).NullN())
+				count += record.NumRows()
+			}
+			if err := reader.Err(); err != nil {
+				return err
+			}
+			assert.Equal(t, int64(10000), count)
+			assert.Equal(t, int64(10000*9999/2), sum)
+			assert.Equal(t, int64(5000), nulls)
+			return nil
+		})
+		assert.NoError(t, err)
+	}
+}
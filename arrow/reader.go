@@ -0,0 +1,443 @@
+// Package arrow converts ClickHouse query results into Apache Arrow records.
+// It is a separate package so the programs which do not use Arrow do not depend on it.
+package arrow
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go"
+	"github.com/ClickHouse/clickhouse-go/lib/column"
+	"github.com/ClickHouse/clickhouse-go/lib/data"
+	"github.com/apache/arrow/go/v9/arrow"
+	"github.com/apache/arrow/go/v9/arrow/array"
+	"github.com/apache/arrow/go/v9/arrow/decimal128"
+	"github.com/apache/arrow/go/v9/arrow/memory"
+	sdecimal "github.com/shopspring/decimal"
+)
+
+// Reader streams a query result as Arrow records, one record for every block received
+// from the server. It implements array.RecordReader:
+//
+//	reader, err := arrow.Query(ctx, ch, "SELECT id, name FROM example")
+//	if err != nil {
+//		return err
+//	}
+//	defer reader.Release()
+//	for reader.Next() {
+//		record := reader.Record()
+//		...
+//	}
+//	return reader.Err()
+type Reader struct {
+	refs   int64
+	mem    memory.Allocator
+	blocks *clickhouse.Blocks
+	schema *arrow.Schema
+	record arrow.Record
+	err    error
+}
+
+var _ array.RecordReader = (*Reader)(nil)
+
+// Query runs the query on the connection with QueryBlocks and converts its result into
+// Arrow records as the blocks are received. The arguments are bound into the query as with Query.
+// With database/sql the connection is available through conn.Raw:
+//
+//	conn.Raw(func(driverConn interface{}) error {
+//		reader, err := arrow.Query(ctx, driverConn.(clickhouse.Clickhouse), "SELECT id, name FROM example")
+//		...
+//	})
+//
+// The connection cannot be used for other queries until the reader is released.
+func Query(ctx context.Context, conn clickhouse.Clickhouse, query string, args ...interface{}) (*Reader, error) {
+	blocks, err := conn.QueryBlocks(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := NewReader(blocks)
+	if err != nil {
+		blocks.Close()
+		return nil, err
+	}
+	return reader, nil
+}
+
+// NewReader returns a reader of the blocks, which are closed when the reader is released.
+//
+// The ClickHouse types are mapped to the Arrow types as follows:
+//
+//	Int8 ... Int64, UInt8 ... UInt64     Int8 ... Int64, Uint8 ... Uint64
+//	Float32, Float64                     Float32, Float64
+//	String, Enum8, Enum16, UUID, IPv4/6  String
+//	FixedString(N)                       FixedSizeBinary(N)
+//	Date                                 Date32
+//	DateTime, DateTime64(P)              Timestamp in seconds, or milli, micro or nanoseconds by P
+//	Decimal(P, S)                        Decimal128(P, S)
+//	Nullable(T)                          T, the field is nullable
+//	Array(T)                             List(T)
+//	Tuple(T1, T2, ...)                   Struct with the fields "1", "2", ...
+func NewReader(blocks *clickhouse.Blocks) (*Reader, error) {
+	schema, err := newSchema(blocks.DataColumns())
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{
+		refs:   1,
+		mem:    memory.NewGoAllocator(),
+		blocks: blocks,
+		schema: schema,
+	}, nil
+}
+
+// Retain increases the reference count of the reader.
+func (reader *Reader) Retain() {
+	atomic.AddInt64(&reader.refs, 1)
+}
+
+// Release decreases the reference count of the reader, when it reaches zero
+// the current record is released and the rest of the result is discarded.
+func (reader *Reader) Release() {
+	if atomic.AddInt64(&reader.refs, -1) != 0 {
+		return
+	}
+	if reader.record != nil {
+		reader.record.Release()
+		reader.record = nil
+	}
+	reader.blocks.Close()
+}
+
+// Schema returns the schema of the records.
+func (reader *Reader) Schema() *arrow.Schema {
+	return reader.schema
+}
+
+// Next converts the next block into a record, it returns false at the end of the result or on error.
+func (reader *Reader) Next() bool {
+	if reader.record != nil {
+		reader.record.Release()
+		reader.record = nil
+	}
+	if reader.err != nil {
+		return false
+	}
+	for reader.blocks.Next() {
+		block := reader.blocks.Block()
+		if block.Rows() == 0 {
+			continue
+		}
+		if reader.record, reader.err = newRecord(reader.mem, reader.schema, block.Data()); reader.err != nil {
+			return false
+		}
+		return true
+	}
+	reader.err = reader.blocks.Err()
+	return false
+}
+
+// Record returns the current record, it is valid until the next call to Next unless it is retained.
+func (reader *Reader) Record() arrow.Record {
+	return reader.record
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (reader *Reader) Err() error {
+	return reader.err
+}
+
+func newSchema(columns []column.Column) (*arrow.Schema, error) {
+	fields := make([]arrow.Field, 0, len(columns))
+	for _, col := range columns {
+		field, err := newField(col.Name(), col)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	return arrow.NewSchema(fields, nil), nil
+}
+
+func newField(name string, col column.Column) (arrow.Field, error) {
+	field := arrow.Field{
+		Name: name,
+	}
+	if null, ok := col.(*column.Nullable); ok {
+		field.Nullable, col = true, null.GetColumn()
+	}
+	var err error
+	if field.Type, err = dataType(col); err != nil {
+		return arrow.Field{}, err
+	}
+	return field, nil
+}
+
+func dataType(col column.Column) (arrow.DataType, error) {
+	switch col := col.(type) {
+	case *column.Int8:
+		return arrow.PrimitiveTypes.Int8, nil
+	case *column.Int16:
+		return arrow.PrimitiveTypes.Int16, nil
+	case *column.Int32:
+		return arrow.PrimitiveTypes.Int32, nil
+	case *column.Int64:
+		return arrow.PrimitiveTypes.Int64, nil
+	case *column.UInt8:
+		return arrow.PrimitiveTypes.Uint8, nil
+	case *column.UInt16:
+		return arrow.PrimitiveTypes.Uint16, nil
+	case *column.UInt32:
+		return arrow.PrimitiveTypes.Uint32, nil
+	case *column.UInt64:
+		return arrow.PrimitiveTypes.Uint64, nil
+	case *column.Float32:
+		return arrow.PrimitiveTypes.Float32, nil
+	case *column.Float64:
+		return arrow.PrimitiveTypes.Float64, nil
+	case *column.String, *column.Enum, *column.UUID, *column.IPv4, *column.IPv6:
+		return arrow.BinaryTypes.String, nil
+	case *column.FixedString:
+		return &arrow.FixedSizeBinaryType{ByteWidth: col.GetLength()}, nil
+	case *column.Date:
+		return arrow.FixedWidthTypes.Date32, nil
+	case *column.DateTime:
+		return &arrow.TimestampType{Unit: arrow.Second, TimeZone: timezone(col.Timezone)}, nil
+	case *column.DateTime64:
+		precision, err := col.GetPrecision()
+		if err != nil {
+			return nil, err
+		}
+		unit := arrow.Nanosecond
+		switch {
+		case precision == 0:
+			unit = arrow.Second
+		case precision <= 3:
+			unit = arrow.Millisecond
+		case precision <= 6:
+			unit = arrow.Microsecond
+		}
+		return &arrow.TimestampType{Unit: unit, TimeZone: timezone(col.Timezone)}, nil
+	case *column.Decimal:
+		return &arrow.Decimal128Type{Precision: int32(col.GetPrecision()), Scale: int32(col.GetScale())}, nil
+	case *column.Array:
+		// the elements of Arrow lists are always nullable
+		field, err := newField("item", col.GetColumn())
+		if err != nil {
+			return nil, err
+		}
+		typ := arrow.DataType(arrow.ListOf(field.Type))
+		for depth := 1; depth < col.Depth(); depth++ {
+			typ = arrow.ListOf(typ)
+		}
+		return typ, nil
+	case *column.Tuple:
+		fields := make([]arrow.Field, 0, len(col.GetColumns()))
+		for i, element := range col.GetColumns() {
+			field, err := newField(strconv.Itoa(i+1), element)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, field)
+		}
+		return arrow.StructOf(fields...), nil
+	}
+	return nil, fmt.Errorf("clickhouse: column %s of type %s cannot be converted to Arrow", col.Name(), col.CHType())
+}
+
+// timezone returns the name of the time zone, or an empty string for the local time zone
+// which has no name Arrow readers understand.
+func timezone(location *time.Location) string {
+	if location == nil || location == time.Local {
+		return ""
+	}
+	return location.String()
+}
+
+// newRecord converts the block into a record of the schema.
+func newRecord(mem memory.Allocator, schema *arrow.Schema, block *data.Block) (arrow.Record, error) {
+	builder := array.NewRecordBuilder(mem, schema)
+	defer builder.Release()
+	for i, values := range block.Values {
+		if err := appendValues(builder.Field(i), schema.Field(i).Type, values); err != nil {
+			return nil, fmt.Errorf("clickhouse: column %s: %w", block.Columns[i].Name(), err)
+		}
+	}
+	return builder.NewRecord(), nil
+}
+
+func appendValues(builder array.Builder, typ arrow.DataType, values column.Values) error {
+	switch values := values.(type) {
+	case column.Int8Values:
+		builder.(*array.Int8Builder).AppendValues(values, nil)
+	case column.Int16Values:
+		builder.(*array.Int16Builder).AppendValues(values, nil)
+	case column.Int32Values:
+		builder.(*array.Int32Builder).AppendValues(values, nil)
+	case column.Int64Values:
+		builder.(*array.Int64Builder).AppendValues(values, nil)
+	case column.UInt8Values:
+		builder.(*array.Uint8Builder).AppendValues(values, nil)
+	case column.UInt16Values:
+		builder.(*array.Uint16Builder).AppendValues(values, nil)
+	case column.UInt32Values:
+		builder.(*array.Uint32Builder).AppendValues(values, nil)
+	case column.UInt64Values:
+		builder.(*array.Uint64Builder).AppendValues(values, nil)
+	case column.Float32Values:
+		builder.(*array.Float32Builder).AppendValues(values, nil)
+	case column.Float64Values:
+		builder.(*array.Float64Builder).AppendValues(values, nil)
+	default:
+		builder.Reserve(values.Len())
+		for row := 0; row < values.Len(); row++ {
+			if err := appendValue(builder, typ, values.Value(row)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// appendValue appends a value as it is returned by database/sql to the builder of the type.
+func appendValue(builder array.Builder, typ arrow.DataType, value interface{}) error {
+	if value == nil {
+		builder.AppendNull()
+		return nil
+	}
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			builder.AppendNull()
+			return nil
+		}
+		value = v.Elem().Interface()
+	}
+	var ok bool
+	switch builder := builder.(type) {
+	case *array.Int8Builder:
+		var v int8
+		if v, ok = value.(int8); ok {
+			builder.Append(v)
+		}
+	case *array.Int16Builder:
+		var v int16
+		if v, ok = value.(int16); ok {
+			builder.Append(v)
+		}
+	case *array.Int32Builder:
+		var v int32
+		if v, ok = value.(int32); ok {
+			builder.Append(v)
+		}
+	case *array.Int64Builder:
+		var v int64
+		if v, ok = value.(int64); ok {
+			builder.Append(v)
+		}
+	case *array.Uint8Builder:
+		var v uint8
+		if v, ok = value.(uint8); ok {
+			builder.Append(v)
+		}
+	case *array.Uint16Builder:
+		var v uint16
+		if v, ok = value.(uint16); ok {
+			builder.Append(v)
+		}
+	case *array.Uint32Builder:
+		var v uint32
+		if v, ok = value.(uint32); ok {
+			builder.Append(v)
+		}
+	case *array.Uint64Builder:
+		var v uint64
+		if v, ok = value.(uint64); ok {
+			builder.Append(v)
+		}
+	case *array.Float32Builder:
+		var v float32
+		if v, ok = value.(float32); ok {
+			builder.Append(v)
+		}
+	case *array.Float64Builder:
+		var v float64
+		if v, ok = value.(float64); ok {
+			builder.Append(v)
+		}
+	case *array.StringBuilder:
+		switch v := value.(type) {
+		case string:
+			builder.Append(v)
+			ok = true
+		case net.IP:
+			builder.Append(v.String())
+			ok = true
+		}
+	case *array.FixedSizeBinaryBuilder:
+		var v string
+		if v, ok = value.(string); ok {
+			builder.Append([]byte(v))
+		}
+	case *array.Date32Builder:
+		var v time.Time
+		if v, ok = value.(time.Time); ok {
+			builder.Append(arrow.Date32FromTime(v))
+		}
+	case *array.TimestampBuilder:
+		var v time.Time
+		if v, ok = value.(time.Time); ok {
+			builder.Append(timestamp(v, typ.(*arrow.TimestampType).Unit))
+		}
+	case *array.Decimal128Builder:
+		var v string
+		if v, ok = value.(string); ok {
+			dec, err := sdecimal.NewFromString(v)
+			if err != nil {
+				return err
+			}
+			builder.Append(decimal128.FromBigInt(dec.Shift(typ.(*arrow.Decimal128Type).Scale).BigInt()))
+		}
+	case *array.ListBuilder:
+		v := reflect.ValueOf(value)
+		if ok = v.Kind() == reflect.Slice; ok {
+			builder.Append(true)
+			elemType := typ.(*arrow.ListType).Elem()
+			for i := 0; i < v.Len(); i++ {
+				if err := appendValue(builder.ValueBuilder(), elemType, v.Index(i).Interface()); err != nil {
+					return err
+				}
+			}
+		}
+	case *array.StructBuilder:
+		var v []interface{}
+		if v, ok = value.([]interface{}); ok && len(v) == builder.NumField() {
+			builder.Append(true)
+			fields := typ.(*arrow.StructType).Fields()
+			for i, element := range v {
+				if err := appendValue(builder.FieldBuilder(i), fields[i].Type, element); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if !ok {
+		return fmt.Errorf("value of type %T cannot be converted to %s", value, typ)
+	}
+	return nil
+}
+
+func timestamp(t time.Time, unit arrow.TimeUnit) arrow.Timestamp {
+	switch unit {
+	case arrow.Second:
+		return arrow.Timestamp(t.Unix())
+	case arrow.Millisecond:
+		return arrow.Timestamp(t.Unix()*1e3 + int64(t.Nanosecond())/1e6)
+	case arrow.Microsecond:
+		return arrow.Timestamp(t.Unix()*1e6 + int64(t.Nanosecond())/1e3)
+	}
+	return arrow.Timestamp(t.UnixNano())
+}
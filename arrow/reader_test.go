@@ -0,0 +1,137 @@
+package arrow
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/lib/binary"
+	"github.com/ClickHouse/clickhouse-go/lib/column"
+	"github.com/ClickHouse/clickhouse-go/lib/data"
+	"github.com/apache/arrow/go/v9/arrow"
+	"github.com/apache/arrow/go/v9/arrow/array"
+	"github.com/apache/arrow/go/v9/arrow/memory"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ArrowRecord(t *testing.T) {
+	var (
+		types = []string{
+			"Int32", "UInt64", "Float64", "String", "FixedString(3)", "Enum8('a' = 1, 'b' = 2)", "UUID",
+			"Date", "DateTime", "DateTime64(3)", "Decimal(18, 2)", "Nullable(Int64)", "Array(String)", "Array(Array(UInt8))",
+		}
+		date  = time.Date(2022, 3, 4, 0, 0, 0, 0, time.UTC)
+		now   = time.Unix(time.Now().Unix(), 0).UTC()
+		one   = int64(1)
+		block = &data.Block{NumColumns: uint64(len(types))}
+	)
+	for i, chType := range types {
+		col, err := column.Factory(string(rune('a'+i)), chType, time.UTC)
+		if !assert.NoError(t, err) {
+			return
+		}
+		block.Columns = append(block.Columns, col)
+	}
+	for _, row := range [][]driver.Value{
+		{int32(-1), uint64(42), 1.5, "x", "abc", "a", "00000000-0000-0000-0000-000000000001",
+			date, now, time.Unix(1, 500e6), int64(12345), &one, []string{"a", "b"}, [][]uint8{{1}, {2, 3}}},
+		{int32(1), uint64(43), 2.5, "y", "def", "b", "00000000-0000-0000-0000-000000000002",
+			date.AddDate(0, 0, 1), now.Add(time.Second), time.Unix(0, -250e6), int64(-5), nil, []string{}, [][]uint8{}},
+	} {
+		if !assert.NoError(t, block.AppendRow(row)) {
+			return
+		}
+	}
+	var buf bytes.Buffer
+	if !assert.NoError(t, block.Write(&data.ServerInfo{}, binary.NewEncoder(&buf))) {
+		return
+	}
+	received := &data.Block{}
+	if !assert.NoError(t, received.Read(&data.ServerInfo{Timezone: time.UTC}, binary.NewDecoder(&buf))) {
+		return
+	}
+	schema, err := newSchema(received.Columns)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, arrow.PrimitiveTypes.Int32, schema.Field(0).Type)
+	assert.Equal(t, &arrow.FixedSizeBinaryType{ByteWidth: 3}, schema.Field(4).Type)
+	assert.Equal(t, &arrow.TimestampType{Unit: arrow.Second, TimeZone: "UTC"}, schema.Field(8).Type)
+	assert.Equal(t, &arrow.TimestampType{Unit: arrow.Millisecond, TimeZone: "UTC"}, schema.Field(9).Type)
+	assert.Equal(t, &arrow.Decimal128Type{Precision: 18, Scale: 2}, schema.Field(10).Type)
+	assert.False(t, schema.Field(10).Nullable)
+	assert.True(t, schema.Field(11).Nullable)
+	assert.Equal(t, "list<item: list<item: uint8, nullable>, nullable>", fmt.Sprint(schema.Field(13).Type))
+
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+	record, err := newRecord(mem, schema, received)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer record.Release()
+	assert.Equal(t, int64(2), record.NumRows())
+	assert.Equal(t, []int32{-1, 1}, record.Column(0).(*array.Int32).Int32Values())
+	assert.Equal(t, []uint64{42, 43}, record.Column(1).(*array.Uint64).Uint64Values())
+	assert.Equal(t, []float64{1.5, 2.5}, record.Column(2).(*array.Float64).Float64Values())
+	assert.Equal(t, "y", record.Column(3).(*array.String).Value(1))
+	assert.Equal(t, []byte("def"), record.Column(4).(*array.FixedSizeBinary).Value(1))
+	assert.Equal(t, "b", record.Column(5).(*array.String).Value(1))
+	assert.Equal(t, "00000000-0000-0000-0000-000000000002", record.Column(6).(*array.String).Value(1))
+	assert.Equal(t, []arrow.Date32{arrow.Date32FromTime(date), arrow.Date32FromTime(date) + 1}, record.Column(7).(*array.Date32).Date32Values())
+	assert.Equal(t, []arrow.Timestamp{arrow.Timestamp(now.Unix()), arrow.Timestamp(now.Unix() + 1)}, record.Column(8).(*array.Timestamp).TimestampValues())
+	assert.Equal(t, []arrow.Timestamp{1500, -250}, record.Column(9).(*array.Timestamp).TimestampValues())
+	if decimals := record.Column(10).(*array.Decimal128); assert.Equal(t, 2, decimals.Len()) {
+		assert.Equal(t, int64(12345), decimals.Value(0).BigInt().Int64())
+		assert.Equal(t, int64(-5), decimals.Value(1).BigInt().Int64())
+	}
+	if nullable := record.Column(11).(*array.Int64); assert.Equal(t, 2, nullable.Len()) {
+		assert.Equal(t, int64(1), nullable.Value(0))
+		assert.True(t, nullable.IsNull(1))
+	}
+	if list := record.Column(12).(*array.List); assert.Equal(t, 2, list.Len()) {
+		assert.Equal(t, []int32{0, 2, 2}, list.Offsets())
+		assert.Equal(t, "a", list.ListValues().(*array.String).Value(0))
+	}
+	if list := record.Column(13).(*array.List); assert.Equal(t, 2, list.Len()) {
+		assert.Equal(t, []int32{0, 2, 2}, list.Offsets())
+		inner := list.ListValues().(*array.List)
+		assert.Equal(t, []int32{0, 1, 3}, inner.Offsets())
+		assert.Equal(t, []uint8{1, 2, 3}, inner.ListValues().(*array.Uint8).Uint8Values())
+	}
+}
+
+func Test_ArrowTuple(t *testing.T) {
+	col, err := column.Factory("t", "Tuple(Int64, Nullable(String), Array(Nullable(Int32)))", time.UTC)
+	if !assert.NoError(t, err) {
+		return
+	}
+	field, err := newField("t", col)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "struct<1: int64, 2: utf8, 3: list<item: int32, nullable>>", fmt.Sprint(field.Type))
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+	builder := array.NewBuilder(mem, field.Type)
+	defer builder.Release()
+	two := int32(2)
+	values := column.InterfaceValues{
+		[]interface{}{int64(1), "x", []*int32{&two, nil}},
+		[]interface{}{int64(2), nil, []*int32{}},
+	}
+	if !assert.NoError(t, appendValues(builder, field.Type, values)) {
+		return
+	}
+	tuples := builder.NewArray().(*array.Struct)
+	defer tuples.Release()
+	assert.Equal(t, []int64{1, 2}, tuples.Field(0).(*array.Int64).Int64Values())
+	assert.True(t, tuples.Field(1).IsNull(1))
+	if list := tuples.Field(2).(*array.List); assert.Equal(t, 2, list.Len()) {
+		assert.Equal(t, int32(2), list.ListValues().(*array.Int32).Value(0))
+		assert.True(t, list.ListValues().IsNull(1))
+	}
+	assert.Error(t, appendValues(builder, field.Type, column.InterfaceValues{[]interface{}{"x", nil, nil}}))
+}
@@ -0,0 +1,71 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+var ErrAsyncInsertOnly = errors.New("async insert supports only insert statements")
+
+// AsyncInsert executes an INSERT statement with the data in the query, e.g.
+// "INSERT INTO example (a, b) VALUES (?, ?)", using the server-side asynchronous
+// inserts: the server buffers the data of many small inserts and writes them as
+// a single part. The arguments are bound into the query as with Exec.
+// The call returns once the buffered data has been written to the table (wait_for_async_insert=1).
+//
+// With database/sql the method is available through the driver connection:
+//
+//	conn.Raw(func(driverConn interface{}) error {
+//		return driverConn.(clickhouse.Clickhouse).AsyncInsert(ctx, "INSERT INTO example VALUES (?, ?)", 1, "a")
+//	})
+func (ch *clickhouse) AsyncInsert(ctx context.Context, query string, args ...interface{}) error {
+	return ch.asyncInsert(ctx, query, true, args)
+}
+
+// AsyncInsertNoWait is AsyncInsert returning as soon as the server has accepted the data
+// into the buffer (fire-and-forget), errors from writing it to the table are not reported.
+func (ch *clickhouse) AsyncInsertNoWait(ctx context.Context, query string, args ...interface{}) error {
+	return ch.asyncInsert(ctx, query, false, args)
+}
+
+func (ch *clickhouse) asyncInsert(ctx context.Context, query string, wait bool, args []interface{}) error {
+	ch.logf("[async insert] wait=%t", wait)
+	switch {
+	case ch.conn.closed:
+		return driver.ErrBadConn
+	case ch.batch != nil:
+		return ErrBatchInProgress
+	case ch.block != nil:
+		return ErrLimitDataRequestInTx
+	case !isInsert(query):
+		return ErrAsyncInsertOnly
+	}
+	stmt := &stmt{
+		ch:       ch,
+		query:    query,
+		numInput: numInput(query),
+	}
+	if stmt.numInput >= 0 && stmt.numInput != len(args) {
+		return fmt.Errorf("async insert: expected %d arguments, got %d", stmt.numInput, len(args))
+	}
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+		if err := ch.CheckNamedValue(&named[i]); err != nil {
+			return err
+		}
+	}
+	finish := ch.watchCancel(ctx)
+	defer finish()
+	ctx = withSettings(ctx, contextSettings{
+		"async_insert":          true,
+		"wait_for_async_insert": wait,
+	})
+	bound, externalTables := stmt.bind(named)
+	if err := ch.sendQuery(withQueryTemplate(ctx, query), bound, externalTables); err != nil {
+		return err
+	}
+	return ch.process()
+}
@@ -0,0 +1,388 @@
+package clickhouse
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/lib/binary"
+	"github.com/ClickHouse/clickhouse-go/lib/column"
+	"github.com/ClickHouse/clickhouse-go/lib/data"
+)
+
+var (
+	ErrBatchInProgress  = errors.New("batch insert is in progress on the connection (use send/abort)")
+	ErrBatchAlreadySent = errors.New("batch has already been sent or aborted")
+	ErrBatchInsertOnly  = errors.New("batch supports only insert statements")
+)
+
+// Batch is an insert statement which data is sent to the server in blocks.
+// Unlike begin/prepare/commit it does not occupy a database/sql transaction.
+// The connection cannot be used for other queries until Send or Abort is called.
+type Batch interface {
+	// Append adds a row, the values must be given in the order of the insert columns.
+	Append(v ...interface{}) error
+	// AppendStruct adds a row from the struct fields, matched to the columns by the `ch` tag or by the field name.
+	// Pointer fields are inserted into Nullable columns as NULL when they are nil.
+	// Every column must have a matching field and every field a matching column, use PrepareStructBatch
+	// to insert only the columns of the fields.
+	AppendStruct(v interface{}) error
+	// AppendMap adds a row from the map of column names to values. Columns missing from
+	// the map get the default value of their type, an unknown column name is an error.
+	AppendMap(row map[string]interface{}) error
+	// Column returns the column with the given index, for appending whole slices of values.
+	Column(c int) BatchColumn
+	// Rows returns the number of rows appended since the last flush.
+	Rows() int
+	// Flush sends the appended rows to the server as a block. Flushed blocks can no longer be aborted.
+	Flush() error
+	// Send flushes the remaining rows and completes the insert.
+	Send() error
+	// Abort discards the rows which have not been flushed yet and completes the insert,
+	// leaving the connection usable for further queries.
+	Abort() error
+}
+
+// BatchColumn appends values to a single column of a batch.
+// The rows are complete once every column has been appended with the same number of values.
+type BatchColumn interface {
+	// Append adds all the values of the slice v, e.g. []int64, []string, []time.Time,
+	// [][]uint8 for Array(UInt8) or []*string for Nullable(String).
+	// The type of the slice is checked once for the whole column.
+	Append(v interface{}) error
+}
+
+// PrepareBatch starts an insert, the query is an INSERT statement without the VALUES part,
+// e.g. "INSERT INTO example (a, b)".
+//
+// With database/sql the batch is available through the driver connection:
+//
+//	conn.Raw(func(driverConn interface{}) error {
+//		batch, err := driverConn.(clickhouse.Clickhouse).PrepareBatch(ctx, "INSERT INTO example")
+//		...
+//	})
+func (ch *clickhouse) PrepareBatch(ctx context.Context, query string) (Batch, error) {
+	ch.logf("[prepare batch] %s", redactQuery(query))
+	switch {
+	case ch.conn.closed:
+		return nil, driver.ErrBadConn
+	case ch.batch != nil:
+		return nil, ErrBatchInProgress
+	case ch.block != nil:
+		return nil, ErrLimitDataRequestInTx
+	case !isInsert(query):
+		return nil, ErrBatchInsertOnly
+	}
+	b := &batch{
+		ch:     ch,
+		ctx:    ctx,
+		query:  splitInsertRe.Split(query, -1)[0] + " VALUES ",
+		finish: ch.watchCancel(ctx),
+	}
+	b.batchID, _ = ctx.Value(batchIDKey).(string)
+	if err := b.begin(); err != nil {
+		b.finish()
+		return nil, err
+	}
+	ch.batch = b
+	return b, nil
+}
+
+// PrepareStructBatch starts an insert into the table of the columns matched by the fields of the struct v,
+// a struct or a pointer to struct (which can be nil), for adding rows with AppendStruct.
+// The columns of the table without a matching field are left out of the insert,
+// so the server fills them with their DEFAULT expressions.
+func (ch *clickhouse) PrepareStructBatch(ctx context.Context, table string, v interface{}) (Batch, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, ErrStructExpected
+	}
+	columns := getStructFields(t).columns()
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("clickhouse: %s has no fields to insert", t)
+	}
+	return ch.PrepareBatch(ctx, "INSERT INTO "+table+" ("+quoteIdentifiers(columns)+")")
+}
+
+var batchIDKey key = "batch_id"
+
+// WithBatchID puts a batch ID into context, making the batches prepared with it idempotent.
+// Every flushed block is sent as a separate insert with insert_deduplication_token
+// set to "<batchID>-<block index>" and is kept encoded until the server acknowledges it.
+// If the connection is lost, the block is sent again on a new connection as allowed by
+// the retry policy (retry_max_attempts in the DSN or WithRetryPolicy), and a block
+// that had already been written is deduplicated by the server.
+//
+// Deduplication is done by the server for Replicated*MergeTree tables, and for
+// MergeTree tables with non_replicated_deduplication_window set.
+// The same batch ID must not be reused for different data.
+func WithBatchID(ctx context.Context, batchID string) context.Context {
+	return context.WithValue(ctx, batchIDKey, batchID)
+}
+
+type batch struct {
+	ch            *clickhouse
+	ctx           context.Context
+	query         string
+	block         *data.Block
+	finish        func()
+	sent          bool
+	inInsert      bool // the insert query is waiting for data on the connection
+	batchID       string
+	blockIndex    int
+	onFlush       func(rows, bytes int) // called after a block has been sent
+	started       time.Time             // when the first row of the current block was appended
+	structType    reflect.Type
+	structIndexes [][]int
+	columnIndexes map[string]int
+}
+
+func (b *batch) Append(v ...interface{}) error {
+	if b.sent {
+		return ErrBatchAlreadySent
+	}
+	row := make([]driver.Value, len(v))
+	for i := range v {
+		nv := driver.NamedValue{Ordinal: i + 1, Value: v[i]}
+		if err := b.ch.CheckNamedValue(&nv); err != nil {
+			return err
+		}
+		row[i] = nv.Value
+	}
+	b.start()
+	if err := b.block.AppendRow(row); err != nil {
+		return err
+	}
+	return b.flushFull()
+}
+
+func (b *batch) AppendStruct(v interface{}) error {
+	value, err := structValue(v)
+	if err != nil {
+		return err
+	}
+	if b.structType != value.Type() {
+		if b.structIndexes, err = getStructFields(value.Type()).insertMapping(b.block.ColumnNames()); err != nil {
+			return err
+		}
+		b.structType = value.Type()
+	}
+	row := make([]interface{}, len(b.structIndexes))
+	for i, index := range b.structIndexes {
+		row[i] = fieldValue(value, index)
+	}
+	return b.Append(row...)
+}
+
+func (b *batch) AppendMap(row map[string]interface{}) error {
+	if b.sent {
+		return ErrBatchAlreadySent
+	}
+	if b.columnIndexes == nil {
+		b.columnIndexes = make(map[string]int, len(b.block.Columns))
+		for i, col := range b.block.Columns {
+			b.columnIndexes[col.Name()] = i
+		}
+	}
+	for name := range row {
+		if _, found := b.columnIndexes[name]; !found {
+			return fmt.Errorf("clickhouse: unknown column %s (columns: %s)", name, strings.Join(b.block.ColumnNames(), ", "))
+		}
+	}
+	values := make([]interface{}, len(b.block.Columns))
+	for i, col := range b.block.Columns {
+		value, found := row[col.Name()]
+		if !found {
+			value = column.DefaultValue(col)
+		}
+		values[i] = value
+	}
+	return b.Append(values...)
+}
+
+func (b *batch) Column(c int) BatchColumn {
+	return &batchColumn{
+		batch:  b,
+		column: c,
+	}
+}
+
+func (b *batch) Rows() int {
+	return int(b.block.NumRows)
+}
+
+func (b *batch) start() {
+	if b.started.IsZero() {
+		b.started = time.Now()
+	}
+}
+
+// flushFull sends the block once it reaches one of the block_size, max_block_bytes
+// or max_block_age limits. A block with partially appended columns is not complete yet.
+func (b *batch) flushFull() error {
+	if b.block.CheckColumns() == nil && b.ch.isBlockFull(b.block, b.started) {
+		return b.Flush()
+	}
+	return nil
+}
+
+func (b *batch) Flush() error {
+	if b.sent {
+		return ErrBatchAlreadySent
+	}
+	if err := b.block.CheckColumns(); err != nil {
+		return err
+	}
+	if b.block.NumRows == 0 {
+		return nil
+	}
+	rows, size := int(b.block.NumRows), b.block.Size()
+	b.ch.logf("[batch] flush block: rows=%d, bytes=%d", rows, size)
+	b.started = time.Time{}
+	if len(b.batchID) != 0 {
+		var encoded bytes.Buffer
+		if err := b.block.Write(&b.ch.ServerInfo, binary.NewEncoder(&encoded)); err != nil {
+			return b.release(err)
+		}
+		if err := b.sendBlock(encoded.Bytes()); err != nil {
+			return err
+		}
+	} else {
+		if err := b.ch.writeBlock(b.block, ""); err != nil {
+			return b.release(err)
+		}
+		if err := b.ch.encoder.Flush(); err != nil {
+			return b.release(err)
+		}
+	}
+	if b.onFlush != nil {
+		b.onFlush(rows, size)
+	}
+	return nil
+}
+
+// sendBlock sends the encoded block as a separate insert and waits for the server
+// to acknowledge it, retrying on a new connection according to the retry policy.
+func (b *batch) sendBlock(encoded []byte) (err error) {
+	policy := b.ch.getRetryPolicy(b.ctx)
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			b.inInsert = false
+			b.ch.conn.Close()
+			err = b.ch.dial()
+		}
+		if err == nil && !b.inInsert {
+			err = b.begin()
+		}
+		if err == nil {
+			if err = b.ch.writeEncodedBlock(encoded); err == nil {
+				if err = b.end(); err == nil {
+					b.blockIndex++
+					return nil
+				}
+			}
+		}
+		if _, ok := err.(*Exception); ok || attempt >= policy.MaxAttempts || b.ctx.Err() != nil || !policy.retryable(err) {
+			return b.release(err)
+		}
+		b.ch.logf("[batch] send block %d: attempt %d of %d failed: %v", b.blockIndex, attempt, policy.MaxAttempts, err)
+		if !policy.wait(b.ctx, attempt) {
+			return b.release(err)
+		}
+	}
+}
+
+func (b *batch) Send() error {
+	if err := b.Flush(); err != nil {
+		return err
+	}
+	b.ch.logf("[batch] send")
+	return b.release(b.end())
+}
+
+func (b *batch) Abort() error {
+	if b.sent {
+		return ErrBatchAlreadySent
+	}
+	b.ch.logf("[batch] abort: discard rows=%d", b.block.NumRows)
+	b.block.Reset()
+	return b.release(b.end())
+}
+
+// deduplicationToken returns the insert_deduplication_token of the current block.
+func (b *batch) deduplicationToken() string {
+	return fmt.Sprintf("%s-%d", b.batchID, b.blockIndex)
+}
+
+// begin sends the insert query, the deduplication token is set for idempotent batches.
+func (b *batch) begin() error {
+	ctx := b.ctx
+	if len(b.batchID) != 0 {
+		ctx = withSettings(ctx, contextSettings{
+			"insert_deduplication_token": b.deduplicationToken(),
+		})
+	}
+	if err := b.ch.sendQuery(ctx, b.query, nil); err != nil {
+		return err
+	}
+	block, err := b.ch.readMeta()
+	if err != nil {
+		return err
+	}
+	if b.block == nil {
+		b.block = block
+	}
+	b.inInsert = true
+	return nil
+}
+
+// end sends an empty block as marker of end of data and waits for the server to complete the insert.
+func (b *batch) end() error {
+	if !b.inInsert {
+		return nil
+	}
+	b.inInsert = false
+	if err := b.ch.writeBlock(&data.Block{}, ""); err != nil {
+		return err
+	}
+	if err := b.ch.encoder.Flush(); err != nil {
+		return err
+	}
+	return b.ch.process()
+}
+
+func (b *batch) release(err error) error {
+	b.sent = true
+	b.finish()
+	b.block.Reset()
+	b.ch.batch = nil
+	if _, ok := err.(*Exception); err != nil && !ok {
+		// the insert is in an unknown state, the connection cannot be reused
+		b.ch.conn.Close()
+	}
+	return err
+}
+
+type batchColumn struct {
+	batch  *batch
+	column int
+}
+
+func (col *batchColumn) Append(v interface{}) error {
+	if col.batch.sent {
+		return ErrBatchAlreadySent
+	}
+	col.batch.start()
+	if err := col.batch.block.AppendColumn(col.column, v); err != nil {
+		return err
+	}
+	return col.batch.flushFull()
+}
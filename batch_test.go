@@ -0,0 +1,99 @@
+package clickhouse
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/lib/binary"
+	"github.com/ClickHouse/clickhouse-go/lib/column"
+	"github.com/ClickHouse/clickhouse-go/lib/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_IsBlockFull(t *testing.T) {
+	col, err := column.Factory("s", "String", time.UTC)
+	if !assert.NoError(t, err) {
+		return
+	}
+	block := &data.Block{Columns: []column.Column{col}, NumColumns: 1}
+	ch := &clickhouse{blockSize: 3}
+	assert.False(t, ch.isBlockFull(block, time.Time{}))
+	assert.NoError(t, block.AppendColumn(0, []string{"aaaa", "bbbb"}))
+	assert.False(t, ch.isBlockFull(block, time.Now()))
+	// bytes
+	ch.maxBlockBytes = 10
+	assert.True(t, ch.isBlockFull(block, time.Now()), "2 * (1 + 4) bytes")
+	ch.maxBlockBytes = 11
+	assert.False(t, ch.isBlockFull(block, time.Now()))
+	// age
+	ch.maxBlockAge = time.Minute
+	assert.False(t, ch.isBlockFull(block, time.Now()))
+	assert.True(t, ch.isBlockFull(block, time.Now().Add(-time.Minute)))
+	// rows
+	assert.NoError(t, block.AppendColumn(0, []string{""}))
+	ch.maxBlockBytes, ch.maxBlockAge = 0, 0
+	assert.True(t, ch.isBlockFull(block, time.Now()))
+}
+
+func Test_BatchAppendDefaults(t *testing.T) {
+	type full struct {
+		ID      uint64   `ch:"id"`
+		Name    string   `ch:"name"`
+		Tags    []string `ch:"tags"`
+		Comment *string  `ch:"comment"`
+	}
+	type partial struct {
+		ID   uint64 `ch:"id"`
+		Name string `ch:"name"`
+	}
+	type extra struct {
+		full
+		Skip string
+	}
+	var blocks [2]*data.Block
+	for i := range blocks {
+		blocks[i] = &data.Block{NumColumns: 4}
+		for _, c := range [][2]string{{"id", "UInt64"}, {"name", "String"}, {"tags", "Array(String)"}, {"comment", "Nullable(String)"}} {
+			col, err := column.Factory(c[0], c[1], time.UTC)
+			if !assert.NoError(t, err) {
+				return
+			}
+			blocks[i].Columns = append(blocks[i].Columns, col)
+		}
+	}
+	var (
+		comment = "comment"
+		ch      = &clickhouse{blockSize: 100}
+		b       = &batch{ch: ch, block: blocks[0]}
+	)
+	assert.NoError(t, b.AppendMap(map[string]interface{}{"id": uint64(1), "comment": &comment}))
+	assert.NoError(t, b.AppendMap(map[string]interface{}{}))
+	assert.NoError(t, b.AppendStruct(full{ID: 2, Name: "name", Tags: []string{}}))
+	if err := b.AppendMap(map[string]interface{}{"id": uint64(3), "unknown": 1}); assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "unknown column unknown")
+	}
+	if err := b.AppendStruct(partial{ID: 3}); assert.Error(t, err, "columns without a field are rejected") {
+		assert.Contains(t, err.Error(), "column tags has no matching field")
+	}
+	if err := b.AppendStruct(extra{}); assert.Error(t, err, "fields without a column are rejected") {
+		assert.Contains(t, err.Error(), "field Skip")
+	}
+	assert.Equal(t, 3, b.Rows())
+
+	expected := blocks[1]
+	for _, row := range [][]driver.Value{
+		{uint64(1), "", []string{}, &comment},
+		{uint64(0), "", []string{}, nil},
+		{uint64(2), "name", []string{}, nil},
+	} {
+		assert.NoError(t, expected.AppendRow(row))
+	}
+	encode := func(block *data.Block) []byte {
+		var buf bytes.Buffer
+		assert.NoError(t, block.Write(&data.ServerInfo{}, binary.NewEncoder(&buf)))
+		return buf.Bytes()
+	}
+	assert.Equal(t, encode(expected), encode(b.block))
+}
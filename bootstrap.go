@@ -2,6 +2,7 @@ package clickhouse
 
 import (
 	"bufio"
+	"context"
 	"database/sql"
 	"database/sql/driver"
 	"fmt"
@@ -80,7 +81,7 @@ func Open(dsn string) (driver.Conn, error) {
 func open(dsn string) (*clickhouse, error) {
 	url, err := url.Parse(dsn)
 	if err != nil {
-		return nil, err
+		return nil, redactURLError(err)
 	}
 	var (
 		hosts             = []string{url.Host}
@@ -94,11 +95,19 @@ func open(dsn string) (*clickhouse, error) {
 		username          = query.Get("username")
 		password          = query.Get("password")
 		blockSize         = 1000000
+		maxBlockBytes     = 0
+		maxBlockAge       time.Duration
 		connTimeout       = DefaultConnTimeout
 		readTimeout       = DefaultReadTimeout
 		writeTimeout      = DefaultWriteTimeout
 		connOpenStrategy  = connOpenRandom
+		retryPolicy       = RetryPolicy{MaxAttempts: 1}
+		resultBuffer      = ResultBuffer{Blocks: DefaultResultBufferBlocks}
+		deadlineMargin    = DefaultDeadlineMargin
+		maxExecutionTime  time.Duration
 		checkConnLiveness = true
+		pingOnBorrow      = false
+		pingThreshold     time.Duration
 	)
 	if len(database) == 0 {
 		database = DefaultDatabase
@@ -106,6 +115,32 @@ func open(dsn string) (*clickhouse, error) {
 	if len(username) == 0 {
 		username = DefaultUsername
 	}
+	var credentialsProvider CredentialsProvider = staticCredentials{
+		Username: username,
+		Password: password,
+	}
+	// the username is read from a file or a variable only along with the password
+	for _, source := range []string{"file", "env"} {
+		if len(query.Get("username_"+source)) != 0 && len(query.Get("password_"+source)) == 0 {
+			return nil, fmt.Errorf("username_%s is given without password_%s", source, source)
+		}
+	}
+	switch name := query.Get("credentials_provider"); {
+	case len(name) != 0:
+		if credentialsProvider = getCredentialsProvider(name); credentialsProvider == nil {
+			return nil, fmt.Errorf("invalid credentials_provider - no provider registered under name %s", name)
+		}
+	case len(query.Get("password_file")) != 0:
+		credentialsProvider = FileCredentials{
+			UsernameFile: query.Get("username_file"),
+			PasswordFile: query.Get("password_file"),
+		}
+	case len(query.Get("password_env")) != 0:
+		credentialsProvider = EnvCredentials{
+			UsernameVar: query.Get("username_env"),
+			PasswordVar: query.Get("password_env"),
+		}
+	}
 	if v, err := strconv.ParseBool(query.Get("no_delay")); err == nil {
 		noDelay = v
 	}
@@ -113,6 +148,9 @@ func open(dsn string) (*clickhouse, error) {
 	if tlsConfigName != "" && tlsConfig == nil {
 		return nil, fmt.Errorf("invalid tls_config - no config registered under name %s", tlsConfigName)
 	}
+	if tlsConfig, err = makeTLSConfig(tlsConfig, query); err != nil {
+		return nil, err
+	}
 	secure = tlsConfig != nil
 	if v, err := strconv.ParseBool(query.Get("secure")); err == nil {
 		secure = v
@@ -132,6 +170,12 @@ func open(dsn string) (*clickhouse, error) {
 	if size, err := strconv.ParseInt(query.Get("block_size"), 10, 64); err == nil {
 		blockSize = int(size)
 	}
+	if size, err := strconv.ParseInt(query.Get("max_block_bytes"), 10, 64); err == nil {
+		maxBlockBytes = int(size)
+	}
+	if duration, err := strconv.ParseFloat(query.Get("max_block_age"), 64); err == nil {
+		maxBlockAge = time.Duration(duration * float64(time.Second))
+	}
 	if altHosts := strings.Split(query.Get("alt_hosts"), ","); len(altHosts) != 0 {
 		for _, host := range altHosts {
 			if len(host) != 0 {
@@ -148,6 +192,28 @@ func open(dsn string) (*clickhouse, error) {
 		connOpenStrategy = connOpenTimeRandom
 	}
 
+	if attempts, err := strconv.ParseInt(query.Get("retry_max_attempts"), 10, 64); err == nil {
+		retryPolicy.MaxAttempts = int(attempts)
+	}
+	if duration, err := strconv.ParseFloat(query.Get("retry_backoff"), 64); err == nil {
+		retryPolicy.Backoff = time.Duration(duration * float64(time.Second))
+	}
+	if duration, err := strconv.ParseFloat(query.Get("retry_max_backoff"), 64); err == nil {
+		retryPolicy.MaxBackoff = time.Duration(duration * float64(time.Second))
+	}
+	if blocks, err := strconv.ParseInt(query.Get("result_buffer_blocks"), 10, 64); err == nil {
+		resultBuffer.Blocks = int(blocks)
+	}
+	if size, err := strconv.ParseInt(query.Get("result_buffer_bytes"), 10, 64); err == nil {
+		resultBuffer.Bytes = size
+	}
+	if duration, err := strconv.ParseFloat(query.Get("deadline_margin"), 64); err == nil {
+		deadlineMargin = time.Duration(duration * float64(time.Second))
+	}
+	if duration, err := strconv.ParseUint(query.Get("max_execution_time"), 10, 64); err == nil {
+		maxExecutionTime = time.Duration(duration) * time.Second
+	}
+
 	settings, err := makeQuerySettings(query)
 	if err != nil {
 		return nil, err
@@ -160,9 +226,11 @@ func open(dsn string) (*clickhouse, error) {
 	if v, err := strconv.ParseBool(query.Get("check_connection_liveness")); err == nil {
 		checkConnLiveness = v
 	}
-	if secure {
-		// There is no way to check the liveness of a secure connection, as long as there is no access to raw TCP net.Conn
-		checkConnLiveness = false
+	if v, err := strconv.ParseBool(query.Get("ping_on_borrow")); err == nil {
+		pingOnBorrow = v
+	}
+	if duration, err := strconv.ParseFloat(query.Get("ping_on_borrow_threshold"), 64); err == nil {
+		pingThreshold = time.Duration(duration * float64(time.Second))
 	}
 
 	var (
@@ -171,7 +239,15 @@ func open(dsn string) (*clickhouse, error) {
 			settings:          settings,
 			compress:          compress,
 			blockSize:         blockSize,
+			maxBlockBytes:     maxBlockBytes,
+			maxBlockAge:       maxBlockAge,
 			checkConnLiveness: checkConnLiveness,
+			pingOnBorrow:      pingOnBorrow,
+			pingThreshold:     pingThreshold,
+			retryPolicy:       retryPolicy,
+			resultBuffer:      resultBuffer,
+			deadlineMargin:    deadlineMargin,
+			maxExecutionTime:  maxExecutionTime,
 			ServerInfo: data.ServerInfo{
 				Timezone: time.Local,
 			},
@@ -181,6 +257,9 @@ func open(dsn string) (*clickhouse, error) {
 	if debug, err := strconv.ParseBool(url.Query().Get("debug")); err == nil && debug {
 		ch.logf = logger.Printf
 	}
+	if v, err := strconv.ParseBool(query.Get("redact_params")); err == nil {
+		ch.redactParams = v
+	}
 	ch.logf("host(s)=%s, database=%s, username=%s",
 		strings.Join(hosts, ", "),
 		database,
@@ -198,20 +277,42 @@ func open(dsn string) (*clickhouse, error) {
 		openStrategy: connOpenStrategy,
 		logf:         ch.logf,
 	}
-	if ch.conn, err = dial(options); err != nil {
+	ch.logger = logger
+	ch.connOptions = options
+	ch.database, ch.username, ch.auth = database, username, credentialsProvider
+	if err := ch.dial(); err != nil {
 		return nil, err
 	}
-	logger.SetPrefix(fmt.Sprintf("[clickhouse][connect=%d]", ch.conn.ident))
+	return &ch, nil
+}
+
+// dial opens a new connection to one of the hosts (chosen by the connection
+// open strategy) and performs the handshake.
+func (ch *clickhouse) dial() error {
+	conn, err := dial(ch.connOptions)
+	if err != nil {
+		return err
+	}
+	// the connection is swapped under the lock, a query being retried may be canceled concurrently
+	ch.Lock()
+	ch.conn = conn
+	ch.logger.SetPrefix(fmt.Sprintf("[clickhouse][connect=%d]", ch.conn.ident))
 	ch.buffer = bufio.NewWriter(ch.conn)
 
 	ch.decoder = binary.NewDecoderWithCompress(ch.conn)
 	ch.encoder = binary.NewEncoderWithCompress(ch.buffer)
+	ch.Unlock()
 
-	if err := ch.hello(database, username, password); err != nil {
+	username, password, err := ch.credentials(context.Background())
+	if err != nil {
 		ch.conn.Close()
-		return nil, err
+		return err
 	}
-	return &ch, nil
+	if err := ch.hello(ch.database, username, password); err != nil {
+		ch.conn.Close()
+		return err
+	}
+	return nil
 }
 
 func (ch *clickhouse) hello(database, username, password string) error {
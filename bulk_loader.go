@@ -0,0 +1,237 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrBulkLoadBatchID is returned by the loads with a context carrying a batch ID (WithBatchID).
+// The rows are spread over the workers and their blocks in a different way on every run, so a load
+// retried with the same deduplication tokens would send other rows, silently dropped by the server.
+var ErrBulkLoadBatchID = errors.New("bulk load does not support batch IDs (WithBatchID)")
+
+// BulkLoaderOptions configure a BulkLoader.
+type BulkLoaderOptions struct {
+	// Workers is the number of connections the rows are inserted over in parallel (default is 4).
+	Workers int
+	// BlockRows and BlockBytes flush the block of a worker once it has this many rows or
+	// bytes of buffered data, on top of block_size, max_block_bytes and max_block_age of the DSN.
+	BlockRows  int
+	BlockBytes int
+	// Progress is called after every block sent to the server with the totals so far.
+	// The calls are serialized.
+	Progress func(BulkLoadStats)
+}
+
+// BulkLoadStats are the throughput metrics of a bulk load.
+type BulkLoadStats struct {
+	Rows    uint64 // rows sent to the server
+	Blocks  uint64 // blocks sent to the server
+	Bytes   uint64 // bytes of uncompressed block data sent to the server
+	Elapsed time.Duration
+}
+
+// RowsPerSecond returns the average number of rows sent per second.
+func (stats BulkLoadStats) RowsPerSecond() float64 {
+	if stats.Elapsed <= 0 {
+		return 0
+	}
+	return float64(stats.Rows) / stats.Elapsed.Seconds()
+}
+
+// BytesPerSecond returns the average number of bytes sent per second.
+func (stats BulkLoadStats) BytesPerSecond() float64 {
+	if stats.Elapsed <= 0 {
+		return 0
+	}
+	return float64(stats.Bytes) / stats.Elapsed.Seconds()
+}
+
+// BulkLoader inserts rows in parallel over several connections of a sql.DB.
+// Every worker takes a connection from the pool and fills its own batch, which is
+// flushed when it reaches the block limits. The first error cancels the other workers.
+//
+// The load is not atomic: blocks sent to the server before an error are persisted.
+// The loads are not idempotent either, see ErrBulkLoadBatchID.
+type BulkLoader struct {
+	db      *sql.DB
+	query   string
+	options BulkLoaderOptions
+}
+
+// NewBulkLoader returns a loader for the insert query, an INSERT statement without
+// the VALUES part as for PrepareBatch, e.g. "INSERT INTO example (a, b)".
+func NewBulkLoader(db *sql.DB, query string, options BulkLoaderOptions) *BulkLoader {
+	if options.Workers <= 0 {
+		options.Workers = 4
+	}
+	return &BulkLoader{
+		db:      db,
+		query:   query,
+		options: options,
+	}
+}
+
+// Load inserts the rows returned by next until it returns io.EOF.
+// The rows are values in the order of the insert columns, as for Batch.Append.
+// next is called from a single goroutine.
+func (loader *BulkLoader) Load(ctx context.Context, next func() ([]interface{}, error)) (BulkLoadStats, error) {
+	if hasBatchID(ctx) {
+		return BulkLoadStats{}, ErrBulkLoadBatchID
+	}
+	load := loader.start(ctx)
+	rows := make(chan []interface{}, loader.options.Workers)
+	go func() {
+		defer close(rows)
+		for {
+			row, err := next()
+			switch {
+			case err == io.EOF:
+				return
+			case err != nil:
+				load.fail(err)
+				return
+			}
+			select {
+			case rows <- row:
+			case <-load.ctx.Done():
+				return
+			}
+		}
+	}()
+	return load.run(loader, rows)
+}
+
+// LoadChannel inserts the rows received from the channel until it is closed.
+// After an error the remaining rows are received and discarded until the channel is closed,
+// so the producer is not blocked.
+func (loader *BulkLoader) LoadChannel(ctx context.Context, rows <-chan []interface{}) (BulkLoadStats, error) {
+	var (
+		stats BulkLoadStats
+		err   = ErrBulkLoadBatchID
+	)
+	if !hasBatchID(ctx) {
+		stats, err = loader.start(ctx).run(loader, rows)
+	}
+	if err != nil {
+		go func() {
+			for range rows {
+			}
+		}()
+	}
+	return stats, err
+}
+
+func (loader *BulkLoader) start(ctx context.Context) *bulkLoad {
+	load := &bulkLoad{
+		begin:    time.Now(),
+		progress: loader.options.Progress,
+	}
+	load.ctx, load.cancel = context.WithCancel(ctx)
+	return load
+}
+
+type bulkLoad struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	begin    time.Time
+	progress func(BulkLoadStats)
+	mutex    sync.Mutex
+	stats    BulkLoadStats
+	err      error
+}
+
+func (load *bulkLoad) run(loader *BulkLoader, rows <-chan []interface{}) (BulkLoadStats, error) {
+	defer load.cancel()
+	var wg sync.WaitGroup
+	for i := 0; i < loader.options.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := load.worker(loader, rows); err != nil {
+				load.fail(err)
+			}
+		}()
+	}
+	wg.Wait()
+	load.mutex.Lock()
+	defer load.mutex.Unlock()
+	load.stats.Elapsed = time.Since(load.begin)
+	return load.stats, load.err
+}
+
+// fail records the first error and cancels the other workers.
+func (load *bulkLoad) fail(err error) {
+	load.mutex.Lock()
+	if load.err == nil {
+		load.err = err
+	}
+	load.mutex.Unlock()
+	load.cancel()
+}
+
+func (load *bulkLoad) flushed(rows, bytes int) {
+	load.mutex.Lock()
+	defer load.mutex.Unlock()
+	load.stats.Rows += uint64(rows)
+	load.stats.Bytes += uint64(bytes)
+	load.stats.Blocks++
+	load.stats.Elapsed = time.Since(load.begin)
+	if load.progress != nil {
+		load.progress(load.stats)
+	}
+}
+
+func hasBatchID(ctx context.Context) bool {
+	batchID, _ := ctx.Value(batchIDKey).(string)
+	return len(batchID) != 0
+}
+
+func (load *bulkLoad) worker(loader *BulkLoader, rows <-chan []interface{}) error {
+	ctx := load.ctx
+	conn, err := loader.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.Raw(func(driverConn interface{}) error {
+		ch, ok := driverConn.(*clickhouse)
+		if !ok {
+			return fmt.Errorf("bulk loader: unexpected driver connection %T", driverConn)
+		}
+		if _, err := ch.PrepareBatch(ctx, loader.query); err != nil {
+			return err
+		}
+		batch := ch.batch
+		batch.onFlush = load.flushed
+		for {
+			select {
+			case <-ctx.Done():
+				// the insert has been canceled by the context together with the connection
+				return ctx.Err()
+			case row, ok := <-rows:
+				if !ok {
+					if err := ctx.Err(); err != nil {
+						return err
+					}
+					return batch.Send()
+				}
+				if err := batch.Append(row...); err != nil {
+					batch.Abort()
+					return err
+				}
+				if (loader.options.BlockRows > 0 && batch.Rows() >= loader.options.BlockRows) ||
+					(loader.options.BlockBytes > 0 && batch.block.Size() >= loader.options.BlockBytes) {
+					if err := batch.Flush(); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	})
+}
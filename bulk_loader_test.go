@@ -0,0 +1,81 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BulkLoadStats(t *testing.T) {
+	stats := BulkLoadStats{Rows: 1000, Bytes: 4000, Blocks: 2, Elapsed: 2 * time.Second}
+	assert.Equal(t, float64(500), stats.RowsPerSecond())
+	assert.Equal(t, float64(2000), stats.BytesPerSecond())
+	assert.Equal(t, float64(0), BulkLoadStats{Rows: 1}.RowsPerSecond())
+}
+
+func Test_BulkLoaderErrors(t *testing.T) {
+	db, err := sql.Open("clickhouse", "tcp://127.0.0.1:1?timeout=0.1")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer db.Close()
+	loader := NewBulkLoader(db, "INSERT INTO example", BulkLoaderOptions{})
+	assert.Equal(t, 4, loader.options.Workers)
+	{
+		rows := make(chan []interface{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			// the producer must not be blocked after the load has failed
+			for i := 0; i < 100; i++ {
+				rows <- []interface{}{i}
+			}
+			close(rows)
+		}()
+		_, err := loader.LoadChannel(context.Background(), rows)
+		assert.Error(t, err)
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("producer is blocked")
+		}
+	}
+	{
+		expected := errors.New("iterator error")
+		_, err := loader.Load(context.Background(), func() ([]interface{}, error) {
+			return nil, expected
+		})
+		assert.Error(t, err)
+	}
+}
+
+func Test_BulkLoaderBatchID(t *testing.T) {
+	db, err := sql.Open("clickhouse", "tcp://127.0.0.1:1?timeout=0.1")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer db.Close()
+	var (
+		loader = NewBulkLoader(db, "INSERT INTO example", BulkLoaderOptions{})
+		ctx    = WithBatchID(context.Background(), "import")
+	)
+	_, err = loader.Load(ctx, func() ([]interface{}, error) {
+		t.Fatal("no row must be read")
+		return nil, io.EOF
+	})
+	assert.Equal(t, ErrBulkLoadBatchID, err)
+	rows := make(chan []interface{})
+	_, err = loader.LoadChannel(ctx, rows)
+	assert.Equal(t, ErrBulkLoadBatchID, err)
+	select {
+	case rows <- []interface{}{1}:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the rows must be discarded")
+	}
+	close(rows)
+}
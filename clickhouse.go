@@ -7,6 +7,7 @@ import (
 	"database/sql/driver"
 	"errors"
 	"fmt"
+	"log"
 	"net"
 	"reflect"
 	"regexp"
@@ -48,16 +49,31 @@ type clickhouse struct {
 	data.ServerInfo
 	data.ClientInfo
 	logf              logger
+	logger            *log.Logger
 	conn              *connect
+	connOptions       connOptions
+	database          string
+	username          string
+	auth              CredentialsProvider
 	block             *data.Block
+	batch             *batch
 	buffer            *bufio.Writer
 	decoder           *binary.Decoder
 	encoder           *binary.Encoder
 	settings          *querySettings
 	compress          bool
 	blockSize         int
+	maxBlockBytes     int
+	maxBlockAge       time.Duration
 	inTransaction     bool
 	checkConnLiveness bool
+	pingOnBorrow      bool
+	pingThreshold     time.Duration
+	retryPolicy       RetryPolicy
+	resultBuffer      ResultBuffer
+	deadlineMargin    time.Duration
+	maxExecutionTime  time.Duration // max_execution_time from the DSN
+	redactParams      bool
 }
 
 func (ch *clickhouse) Prepare(query string) (driver.Stmt, error) {
@@ -69,10 +85,12 @@ func (ch *clickhouse) PrepareContext(ctx context.Context, query string) (driver.
 }
 
 func (ch *clickhouse) prepareContext(ctx context.Context, query string) (driver.Stmt, error) {
-	ch.logf("[prepare] %s", query)
+	ch.logf("[prepare] %s", redactQuery(query))
 	switch {
 	case ch.conn.closed:
 		return nil, driver.ErrBadConn
+	case ch.batch != nil:
+		return nil, ErrBatchInProgress
 	case ch.block != nil:
 		return nil, ErrLimitDataRequestInTx
 	case isInsert(query):
@@ -124,6 +142,8 @@ func (ch *clickhouse) beginTx(ctx context.Context, opts txOptions) (*clickhouse,
 		return nil, sql.ErrTxDone
 	case ch.conn.closed:
 		return nil, driver.ErrBadConn
+	case ch.batch != nil:
+		return nil, ErrBatchInProgress
 	}
 
 	// Perform a stale connection check. We only perform this check in beginTx,
@@ -145,6 +165,29 @@ func (ch *clickhouse) beginTx(ctx context.Context, opts txOptions) (*clickhouse,
 	return ch, nil
 }
 
+// ResetSession is called by database/sql before a pooled connection is reused.
+// With ping_on_borrow enabled, a connection that has been idle for longer than
+// ping_on_borrow_threshold is pinged, and reported as bad if the ping fails,
+// so database/sql retries the request with another connection.
+func (ch *clickhouse) ResetSession(ctx context.Context) error {
+	switch {
+	case ch.conn.closed:
+		return driver.ErrBadConn
+	case ch.batch != nil:
+		// the batch was neither sent nor aborted, the insert cannot be continued
+		ch.Close()
+		return driver.ErrBadConn
+	case !ch.pingOnBorrow || ch.conn.idle() < ch.pingThreshold:
+		return nil
+	}
+	if err := ch.ping(ctx); err != nil {
+		ch.logf("[reset session] closing stale connection: %v", err)
+		ch.Close()
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
 func (ch *clickhouse) Commit() error {
 	ch.logf("[commit] tx=%t, data=%t", ch.inTransaction, ch.block != nil)
 	defer func() {
@@ -176,6 +219,22 @@ func (ch *clickhouse) Commit() error {
 	return nil
 }
 
+// isBlockFull reports whether the insert block has to be sent to the server: it has
+// reached block_size rows or max_block_bytes of buffered data, or its first row was
+// appended longer than max_block_age ago. It is only called when a row is appended,
+// an aged block is not sent until then.
+func (ch *clickhouse) isBlockFull(block *data.Block, started time.Time) bool {
+	switch {
+	case block.NumRows >= uint64(ch.blockSize):
+		return true
+	case ch.maxBlockBytes > 0 && block.Size() >= ch.maxBlockBytes:
+		return true
+	case ch.maxBlockAge > 0 && !started.IsZero() && time.Since(started) >= ch.maxBlockAge:
+		return true
+	}
+	return false
+}
+
 func (ch *clickhouse) Rollback() error {
 	ch.logf("[rollback] tx=%t, data=%t", ch.inTransaction, ch.block != nil)
 	if !ch.inTransaction {
@@ -207,42 +266,25 @@ func (ch *clickhouse) CheckNamedValue(nv *driver.NamedValue) error {
 	case net.IP, *net.IP:
 		return nil
 	case driver.Valuer:
-		value, err := v.Value()
+		value, err := driverValue(v)
 		if err != nil {
 			return err
 		}
 		nv.Value = value
 	default:
 		switch value := reflect.ValueOf(nv.Value); value.Kind() {
-		case reflect.Slice:
-			return nil
-		case reflect.Bool:
-			nv.Value = uint8(0)
-			if value.Bool() {
-				nv.Value = uint8(1)
+		case reflect.Slice, reflect.Map:
+			// slices of user types (e.g. []UserID) are converted element by element and
+			// maps into their keys and values, as ArrayOf and MapValue do
+			value, err := driverValue(nv.Value)
+			if err != nil {
+				return err
+			}
+			nv.Value = value
+		default:
+			if v, ok := basicValue(value); ok {
+				nv.Value = v
 			}
-		case reflect.Int8:
-			nv.Value = int8(value.Int())
-		case reflect.Int16:
-			nv.Value = int16(value.Int())
-		case reflect.Int32:
-			nv.Value = int32(value.Int())
-		case reflect.Int64:
-			nv.Value = value.Int()
-		case reflect.Uint8:
-			nv.Value = uint8(value.Uint())
-		case reflect.Uint16:
-			nv.Value = uint16(value.Uint())
-		case reflect.Uint32:
-			nv.Value = uint32(value.Uint())
-		case reflect.Uint64:
-			nv.Value = uint64(value.Uint())
-		case reflect.Float32:
-			nv.Value = float32(value.Float())
-		case reflect.Float64:
-			nv.Value = float64(value.Float())
-		case reflect.String:
-			nv.Value = value.String()
 		}
 	}
 	return nil
@@ -250,6 +292,7 @@ func (ch *clickhouse) CheckNamedValue(nv *driver.NamedValue) error {
 
 func (ch *clickhouse) Close() error {
 	ch.block = nil
+	ch.batch = nil
 	return ch.conn.Close()
 }
 
@@ -303,6 +346,8 @@ func (ch *clickhouse) process() error {
 
 func (ch *clickhouse) cancel() error {
 	ch.logf("[cancel request]")
+	ch.Lock()
+	defer ch.Unlock()
 	// even if we fail to write the cancel, we still need to close
 	err := ch.encoder.Uvarint(protocol.ClientCancel)
 	if err == nil {
@@ -346,9 +391,5 @@ func (ch *clickhouse) ExecContext(ctx context.Context, query string,
 	if err != nil {
 		return nil, err
 	}
-	dargs := make([]driver.Value, len(args))
-	for i, nv := range args {
-		dargs[i] = nv.Value
-	}
-	return stmt.Exec(dargs)
+	return stmt.(driver.StmtExecContext).ExecContext(ctx, args)
 }
@@ -0,0 +1,55 @@
+package clickhouse_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AsyncInsert(t *testing.T) {
+	const (
+		ddl = `
+			CREATE TABLE clickhouse_test_async_insert (
+				id      UInt64,
+				name    String,
+				created DateTime
+			) Engine=Memory
+		`
+	)
+	ctx := context.Background()
+	if connect, err := sql.Open("clickhouse", "tcp://127.0.0.1:9000?debug=true"); assert.NoError(t, err) {
+		if _, err := connect.Exec("DROP TABLE IF EXISTS clickhouse_test_async_insert"); assert.NoError(t, err) {
+			if _, err := connect.Exec(ddl); assert.NoError(t, err) {
+				conn, err := connect.Conn(ctx)
+				if !assert.NoError(t, err) {
+					return
+				}
+				defer conn.Close()
+				err = conn.Raw(func(driverConn interface{}) error {
+					ch := driverConn.(clickhouse.Clickhouse)
+					for i := 0; i < 10; i++ {
+						if err := ch.AsyncInsert(ctx, "INSERT INTO clickhouse_test_async_insert VALUES (?, ?, ?)", uint64(i), "it's", time.Now()); err != nil {
+							return err
+						}
+					}
+					if err := ch.AsyncInsertNoWait(ctx, "INSERT INTO clickhouse_test_async_insert VALUES (?, ?, ?)", uint64(10), "fire and forget", time.Now()); err != nil {
+						return err
+					}
+					assert.Error(t, ch.AsyncInsert(ctx, "INSERT INTO clickhouse_test_async_insert VALUES (?, ?, ?)", uint64(1)))
+					assert.Equal(t, clickhouse.ErrAsyncInsertOnly, ch.AsyncInsert(ctx, "SELECT 1"))
+					return nil
+				})
+				if assert.NoError(t, err) {
+					var count uint64
+					if err := conn.QueryRowContext(ctx, "SELECT count() FROM clickhouse_test_async_insert WHERE name = 'it''s'").Scan(&count); assert.NoError(t, err) {
+						assert.Equal(t, uint64(10), count)
+					}
+				}
+			}
+		}
+	}
+}
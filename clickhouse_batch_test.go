@@ -0,0 +1,351 @@
+package clickhouse_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Batch(t *testing.T) {
+	const (
+		ddl = `
+			CREATE TABLE clickhouse_test_batch (
+				id     UInt64,
+				name   String,
+				tags   Array(String)
+			) Engine=Memory
+		`
+	)
+	type row struct {
+		ID   uint64   `ch:"id"`
+		Name string   `ch:"name"`
+		Tags []string `ch:"tags"`
+	}
+	ctx := context.Background()
+	if connect, err := sql.Open("clickhouse", "tcp://127.0.0.1:9000?debug=true"); assert.NoError(t, err) {
+		if _, err := connect.Exec("DROP TABLE IF EXISTS clickhouse_test_batch"); assert.NoError(t, err) {
+			if _, err := connect.Exec(ddl); assert.NoError(t, err) {
+				conn, err := connect.Conn(ctx)
+				if !assert.NoError(t, err) {
+					return
+				}
+				defer conn.Close()
+				err = conn.Raw(func(driverConn interface{}) error {
+					ch := driverConn.(clickhouse.Clickhouse)
+					{
+						batch, err := ch.PrepareBatch(ctx, "INSERT INTO clickhouse_test_batch")
+						if err != nil {
+							return err
+						}
+						assert.NoError(t, batch.Append(uint64(1), "discarded", []string{}))
+						_, err = ch.PrepareBatch(ctx, "INSERT INTO clickhouse_test_batch")
+						assert.Equal(t, clickhouse.ErrBatchInProgress, err)
+						assert.NoError(t, batch.Abort())
+						assert.Equal(t, clickhouse.ErrBatchAlreadySent, batch.Send())
+					}
+					batch, err := ch.PrepareBatch(ctx, "INSERT INTO clickhouse_test_batch")
+					if err != nil {
+						return err
+					}
+					for i := 0; i < 10; i++ {
+						if err := batch.Append(uint64(i), "append", []string{"a", "b"}); err != nil {
+							return err
+						}
+					}
+					assert.Equal(t, 10, batch.Rows())
+					assert.NoError(t, batch.Flush())
+					for i := 10; i < 20; i++ {
+						if err := batch.AppendStruct(&row{ID: uint64(i), Name: "struct", Tags: []string{"c"}}); err != nil {
+							return err
+						}
+					}
+					assert.Error(t, batch.AppendStruct(struct{ ID uint64 }{}))
+					return batch.Send()
+				})
+				if assert.NoError(t, err) {
+					var count, discarded uint64
+					if err := conn.QueryRowContext(ctx, "SELECT count(), countIf(name = 'discarded') FROM clickhouse_test_batch").Scan(&count, &discarded); assert.NoError(t, err) {
+						assert.Equal(t, uint64(20), count)
+						assert.Equal(t, uint64(0), discarded)
+					}
+				}
+			}
+		}
+	}
+}
+
+func Test_DirectBatch(t *testing.T) {
+	if connect, err := clickhouse.OpenDirect("tcp://127.0.0.1:9000?debug=true"); assert.NoError(t, err) {
+		defer connect.Close()
+		{
+			stmt, _ := connect.Prepare("DROP TABLE IF EXISTS clickhouse_test_direct_batch")
+			stmt.Exec([]driver.Value{})
+		}
+		{
+			stmt, _ := connect.Prepare("CREATE TABLE clickhouse_test_direct_batch (value Int32) Engine=Memory")
+			if _, err := stmt.Exec([]driver.Value{}); !assert.NoError(t, err) {
+				return
+			}
+		}
+		batch, err := connect.PrepareBatch(context.Background(), "INSERT INTO clickhouse_test_direct_batch (value)")
+		if assert.NoError(t, err) {
+			for i := 0; i < 100; i++ {
+				assert.NoError(t, batch.Append(int32(i)))
+			}
+			assert.NoError(t, batch.Send())
+		}
+		_, err = connect.PrepareBatch(context.Background(), "SELECT 1")
+		assert.Equal(t, clickhouse.ErrBatchInsertOnly, err)
+	}
+}
+
+func Test_StructMapping(t *testing.T) {
+	const (
+		ddl = `
+			CREATE TABLE clickhouse_test_struct_mapping (
+				id      UInt64,
+				name    String,
+				comment Nullable(String)
+			) Engine=Memory
+		`
+	)
+	type base struct {
+		ID uint64 `ch:"id"`
+	}
+	type row struct {
+		base
+		Name    string  `ch:"name"`
+		Comment *string `ch:"comment"`
+		Skip    string  `ch:"-"`
+	}
+	ctx := context.Background()
+	if connect, err := sql.Open("clickhouse", "tcp://127.0.0.1:9000?debug=true"); assert.NoError(t, err) {
+		if _, err := connect.Exec("DROP TABLE IF EXISTS clickhouse_test_struct_mapping"); assert.NoError(t, err) {
+			if _, err := connect.Exec(ddl); assert.NoError(t, err) {
+				conn, err := connect.Conn(ctx)
+				if !assert.NoError(t, err) {
+					return
+				}
+				defer conn.Close()
+				comment := "comment"
+				err = conn.Raw(func(driverConn interface{}) error {
+					batch, err := driverConn.(clickhouse.Clickhouse).PrepareBatch(ctx, "INSERT INTO clickhouse_test_struct_mapping")
+					if err != nil {
+						return err
+					}
+					if err := batch.AppendStruct(row{base: base{ID: 1}, Name: "null"}); err != nil {
+						return err
+					}
+					if err := batch.AppendStruct(&row{base: base{ID: 2}, Name: "value", Comment: &comment}); err != nil {
+						return err
+					}
+					return batch.Send()
+				})
+				if !assert.NoError(t, err) {
+					return
+				}
+				var rows []row
+				if err := clickhouse.Select(ctx, conn, &rows, "SELECT id, name, comment FROM clickhouse_test_struct_mapping ORDER BY id"); assert.NoError(t, err) {
+					if assert.Len(t, rows, 2) {
+						assert.Equal(t, row{base: base{ID: 1}, Name: "null"}, rows[0])
+						assert.Equal(t, row{base: base{ID: 2}, Name: "value", Comment: &comment}, rows[1])
+					}
+				}
+				var ptrs []*row
+				if err := clickhouse.Select(ctx, conn, &ptrs, "SELECT id, name FROM clickhouse_test_struct_mapping ORDER BY id"); assert.NoError(t, err) {
+					assert.Len(t, ptrs, 2)
+				}
+				err = clickhouse.Select(ctx, conn, &rows, "SELECT id, name, 1 AS extra FROM clickhouse_test_struct_mapping")
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), "column extra has no matching field")
+				}
+				if sqlRows, err := conn.QueryContext(ctx, "SELECT id, comment FROM clickhouse_test_struct_mapping WHERE id = 2"); assert.NoError(t, err) {
+					defer sqlRows.Close()
+					var r row
+					if assert.True(t, sqlRows.Next()) && assert.NoError(t, clickhouse.ScanStruct(sqlRows, &r)) {
+						assert.Equal(t, uint64(2), r.ID)
+						assert.Equal(t, &comment, r.Comment)
+					}
+				}
+			}
+		}
+	}
+}
+
+func Test_BatchColumns(t *testing.T) {
+	const (
+		ddl = `
+			CREATE TABLE clickhouse_test_batch_columns (
+				id      UInt64,
+				name    String,
+				created DateTime,
+				tags    Array(String),
+				comment Nullable(String)
+			) Engine=Memory
+		`
+	)
+	ctx := context.Background()
+	if connect, err := sql.Open("clickhouse", "tcp://127.0.0.1:9000?debug=true"); assert.NoError(t, err) {
+		if _, err := connect.Exec("DROP TABLE IF EXISTS clickhouse_test_batch_columns"); assert.NoError(t, err) {
+			if _, err := connect.Exec(ddl); assert.NoError(t, err) {
+				conn, err := connect.Conn(ctx)
+				if !assert.NoError(t, err) {
+					return
+				}
+				defer conn.Close()
+				comment := "comment"
+				err = conn.Raw(func(driverConn interface{}) error {
+					batch, err := driverConn.(clickhouse.Clickhouse).PrepareBatch(ctx, "INSERT INTO clickhouse_test_batch_columns")
+					if err != nil {
+						return err
+					}
+					columns := []interface{}{
+						[]uint64{1, 2, 3},
+						[]string{"a", "b", "c"},
+						[]time.Time{time.Now(), time.Now(), time.Now()},
+						[][]string{{"x"}, {}, {"y", "z"}},
+						[]*string{&comment, nil, nil},
+					}
+					for c, values := range columns {
+						if err := batch.Column(c).Append(values); err != nil {
+							return err
+						}
+						if c == 0 {
+							assert.Error(t, batch.Flush(), "columns are uneven")
+						}
+					}
+					assert.Equal(t, 3, batch.Rows())
+					return batch.Send()
+				})
+				if assert.NoError(t, err) {
+					var count, tags, comments uint64
+					if err := conn.QueryRowContext(ctx, "SELECT count(), sum(length(tags)), count(comment) FROM clickhouse_test_batch_columns").Scan(&count, &tags, &comments); assert.NoError(t, err) {
+						assert.Equal(t, uint64(3), count)
+						assert.Equal(t, uint64(3), tags)
+						assert.Equal(t, uint64(1), comments)
+					}
+				}
+			}
+		}
+	}
+}
+
+func Test_BatchDeduplication(t *testing.T) {
+	const (
+		ddl = `
+			CREATE TABLE clickhouse_test_batch_deduplication (
+				id   UInt64,
+				name String
+			) Engine=MergeTree ORDER BY id SETTINGS non_replicated_deduplication_window = 100
+		`
+	)
+	ctx := clickhouse.WithBatchID(context.Background(), "clickhouse_test_batch_deduplication")
+	if connect, err := sql.Open("clickhouse", "tcp://127.0.0.1:9000?debug=true&retry_max_attempts=3"); assert.NoError(t, err) {
+		if _, err := connect.Exec("DROP TABLE IF EXISTS clickhouse_test_batch_deduplication"); assert.NoError(t, err) {
+			if _, err := connect.Exec(ddl); assert.NoError(t, err) {
+				conn, err := connect.Conn(ctx)
+				if !assert.NoError(t, err) {
+					return
+				}
+				defer conn.Close()
+				insert := func() error {
+					return conn.Raw(func(driverConn interface{}) error {
+						batch, err := driverConn.(clickhouse.Clickhouse).PrepareBatch(ctx, "INSERT INTO clickhouse_test_batch_deduplication")
+						if err != nil {
+							return err
+						}
+						for i := 0; i < 10; i++ {
+							if err := batch.Append(uint64(i), "name"); err != nil {
+								return err
+							}
+							if i%4 == 3 {
+								if err := batch.Flush(); err != nil {
+									return err
+								}
+							}
+						}
+						return batch.Send()
+					})
+				}
+				// the second insert of the same batch must be deduplicated block by block
+				for i := 0; i < 2; i++ {
+					if !assert.NoError(t, insert()) {
+						return
+					}
+				}
+				var count uint64
+				if err := conn.QueryRowContext(ctx, "SELECT count() FROM clickhouse_test_batch_deduplication").Scan(&count); assert.NoError(t, err) {
+					assert.Equal(t, uint64(10), count)
+				}
+			}
+		}
+	}
+}
+
+func Test_BatchDefaults(t *testing.T) {
+	const (
+		ddl = `
+			CREATE TABLE clickhouse_test_batch_defaults (
+				id      UInt64,
+				name    String,
+				tags    Array(String),
+				comment Nullable(String),
+				created Date DEFAULT toDate('2021-01-01')
+			) Engine=Memory
+		`
+	)
+	type partial struct {
+		ID   uint64 `ch:"id"`
+		Name string `ch:"name"`
+	}
+	ctx := context.Background()
+	if connect, err := sql.Open("clickhouse", "tcp://127.0.0.1:9000?debug=true"); assert.NoError(t, err) {
+		if _, err := connect.Exec("DROP TABLE IF EXISTS clickhouse_test_batch_defaults"); assert.NoError(t, err) {
+			if _, err := connect.Exec(ddl); assert.NoError(t, err) {
+				conn, err := connect.Conn(ctx)
+				if !assert.NoError(t, err) {
+					return
+				}
+				defer conn.Close()
+				err = conn.Raw(func(driverConn interface{}) error {
+					batch, err := driverConn.(clickhouse.Clickhouse).PrepareBatch(ctx, "INSERT INTO clickhouse_test_batch_defaults (id, name, tags, comment)")
+					if err != nil {
+						return err
+					}
+					if err := batch.AppendMap(map[string]interface{}{"id": uint64(1), "tags": []string{"a"}}); err != nil {
+						return err
+					}
+					assert.Error(t, batch.AppendStruct(partial{ID: 2, Name: "struct"}), "the columns tags and comment have no fields")
+					assert.Error(t, batch.AppendMap(map[string]interface{}{"id": uint64(3), "created": time.Now()}))
+					if err := batch.Send(); err != nil {
+						return err
+					}
+					if batch, err = driverConn.(clickhouse.Clickhouse).PrepareStructBatch(ctx, "clickhouse_test_batch_defaults", (*partial)(nil)); err != nil {
+						return err
+					}
+					if err := batch.AppendStruct(partial{ID: 2, Name: "struct"}); err != nil {
+						return err
+					}
+					return batch.Send()
+				})
+				if assert.NoError(t, err) {
+					var count, tags, comments, created uint64
+					if err := conn.QueryRowContext(ctx, `
+						SELECT count(), sum(length(tags)), count(comment), countIf(created = toDate('2021-01-01'))
+						FROM clickhouse_test_batch_defaults
+					`).Scan(&count, &tags, &comments, &created); assert.NoError(t, err) {
+						assert.Equal(t, uint64(2), count)
+						assert.Equal(t, uint64(1), tags)
+						assert.Equal(t, uint64(0), comments)
+						assert.Equal(t, uint64(2), created)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package clickhouse_test
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BulkLoader(t *testing.T) {
+	const (
+		ddl = `
+			CREATE TABLE clickhouse_test_bulk_loader (
+				id   UInt64,
+				name String
+			) Engine=Memory
+		`
+	)
+	if connect, err := sql.Open("clickhouse", "tcp://127.0.0.1:9000?debug=false"); assert.NoError(t, err) {
+		if _, err := connect.Exec("DROP TABLE IF EXISTS clickhouse_test_bulk_loader"); assert.NoError(t, err) {
+			if _, err := connect.Exec(ddl); assert.NoError(t, err) {
+				var (
+					progress int
+					loader   = clickhouse.NewBulkLoader(connect, "INSERT INTO clickhouse_test_bulk_loader", clickhouse.BulkLoaderOptions{
+						Workers:   3,
+						BlockRows: 1000,
+						Progress: func(stats clickhouse.BulkLoadStats) {
+							progress++
+						},
+					})
+					i int
+				)
+				stats, err := loader.Load(context.Background(), func() ([]interface{}, error) {
+					if i++; i > 10000 {
+						return nil, io.EOF
+					}
+					return []interface{}{uint64(i), "name"}, nil
+				})
+				if assert.NoError(t, err) {
+					assert.Equal(t, uint64(10000), stats.Rows)
+					assert.Equal(t, int(stats.Blocks), progress)
+					assert.True(t, stats.Blocks >= 10)
+					var count uint64
+					if err := connect.QueryRow("SELECT count() FROM clickhouse_test_bulk_loader").Scan(&count); assert.NoError(t, err) {
+						assert.Equal(t, uint64(10000), count)
+					}
+				}
+				rows := make(chan []interface{})
+				go func() {
+					defer close(rows)
+					rows <- []interface{}{uint64(1), "name"}
+					rows <- []interface{}{"invalid"}
+				}()
+				_, err = loader.LoadChannel(context.Background(), rows)
+				assert.Error(t, err)
+			}
+		}
+	}
+}
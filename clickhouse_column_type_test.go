@@ -0,0 +1,48 @@
+package clickhouse_test
+
+import (
+	"database/sql"
+	"math"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ColumnTypes(t *testing.T) {
+	if connect, err := sql.Open("clickhouse", "tcp://127.0.0.1:9000?debug=true"); assert.NoError(t, err) {
+		defer connect.Close()
+		rows, err := connect.Query(`
+			SELECT
+				'a'
+				, toFixedString('abc', 3)
+				, toNullable(toFixedString('ab', 2))
+				, toUInt64(1)
+				, [toNullable(toDecimal64(1, 2))]
+		`)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer rows.Close()
+		columnTypes, err := rows.ColumnTypes()
+		if !assert.NoError(t, err) || !assert.Len(t, columnTypes, 5) {
+			return
+		}
+		for i, expected := range []struct {
+			length int64
+			ok     bool
+		}{{math.MaxInt64, true}, {3, true}, {2, true}, {0, false}, {0, false}} {
+			length, ok := columnTypes[i].Length()
+			assert.Equal(t, expected.length, length)
+			assert.Equal(t, expected.ok, ok)
+		}
+		typ, err := clickhouse.ParseColumnType(columnTypes[4].DatabaseTypeName())
+		if assert.NoError(t, err) && assert.Len(t, typ.Elements, 1) {
+			assert.Equal(t, "Array", typ.Name)
+			assert.True(t, typ.Elements[0].Nullable())
+			if decimal := typ.Elements[0].Elements[0]; assert.Equal(t, "Decimal", decimal.Name) {
+				assert.Equal(t, []string{"18", "2"}, decimal.Params)
+			}
+		}
+	}
+}
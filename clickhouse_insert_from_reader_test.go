@@ -0,0 +1,57 @@
+package clickhouse_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_InsertFromReader(t *testing.T) {
+	const (
+		ddl = `
+			CREATE TABLE clickhouse_test_insert_from_reader (
+				id      UInt64,
+				name    String,
+				tags    Array(String),
+				comment Nullable(String)
+			) Engine=Memory
+		`
+	)
+	ctx := context.Background()
+	if connect, err := sql.Open("clickhouse", "tcp://127.0.0.1:9000?debug=true"); assert.NoError(t, err) {
+		if _, err := connect.Exec("DROP TABLE IF EXISTS clickhouse_test_insert_from_reader"); assert.NoError(t, err) {
+			if _, err := connect.Exec(ddl); assert.NoError(t, err) {
+				conn, err := connect.Conn(ctx)
+				if !assert.NoError(t, err) {
+					return
+				}
+				defer conn.Close()
+				insert := func(format clickhouse.InputFormat, data string) error {
+					return conn.Raw(func(driverConn interface{}) error {
+						return driverConn.(clickhouse.Clickhouse).InsertFromReader(ctx, "clickhouse_test_insert_from_reader", format, strings.NewReader(data))
+					})
+				}
+				assert.NoError(t, insert(clickhouse.FormatCSV, "1,a,\"['x','y']\",\\N\n2,\"b\nc\",[],comment\n"))
+				assert.NoError(t, insert(clickhouse.FormatTSVWithNames, "name\tid\nd\t3\n"))
+				assert.NoError(t, insert(clickhouse.FormatJSONEachRow, `{"id": 4, "name": "e", "tags": ["z"], "comment": null}`+"\n"))
+				err = insert(clickhouse.FormatCSV, "5,f,[],\\N\nx,g,[],\\N\n")
+				var inputErr *clickhouse.InputError
+				if assert.True(t, errors.As(err, &inputErr)) {
+					assert.Equal(t, 2, inputErr.Line)
+					assert.Equal(t, "id", inputErr.Column)
+				}
+				var count, tags, comments uint64
+				if err := conn.QueryRowContext(ctx, "SELECT count(), sum(length(tags)), count(comment) FROM clickhouse_test_insert_from_reader").Scan(&count, &tags, &comments); assert.NoError(t, err) {
+					assert.Equal(t, uint64(4), count)
+					assert.Equal(t, uint64(3), tags)
+					assert.Equal(t, uint64(1), comments)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package clickhouse_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_QueryBlocks(t *testing.T) {
+	ctx := context.Background()
+	if connect, err := sql.Open("clickhouse", "tcp://127.0.0.1:9000?debug=true"); assert.NoError(t, err) {
+		conn, err := connect.Conn(ctx)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		err = conn.Raw(func(driverConn interface{}) error {
+			blocks, err := driverConn.(clickhouse.Clickhouse).QueryBlocks(ctx, `
+				SELECT number, toString(number), toDateTime(number) FROM system.numbers LIMIT ?
+				SETTINGS max_block_size = 1000
+			`, 10000)
+			if err != nil {
+				return err
+			}
+			defer blocks.Close()
+			assert.Equal(t, []string{"number", "toString(number)", "toDateTime(number)"}, blocks.Columns())
+			var count, sum, numBlocks uint64
+			for blocks.Next() {
+				block := blocks.Block()
+				numbers, err := block.UInt64s(0)
+				if err != nil {
+					return err
+				}
+				names, err := block.Strings(1)
+				if err != nil {
+					return err
+				}
+				times, err := block.Times(2)
+				if err != nil {
+					return err
+				}
+				assert.Equal(t, block.Rows(), len(numbers))
+				assert.Equal(t, len(numbers), len(names))
+				assert.Equal(t, len(numbers), len(times))
+				for _, n := range numbers {
+					sum += n
+				}
+				count += uint64(block.Rows())
+				numBlocks++
+			}
+			if err := blocks.Err(); err != nil {
+				return err
+			}
+			assert.Equal(t, uint64(10000), count)
+			assert.Equal(t, uint64(10000*9999/2), sum)
+			assert.True(t, numBlocks >= 10)
+			return nil
+		})
+		assert.NoError(t, err)
+		// the connection is released after the blocks are closed
+		var one int
+		if err := conn.QueryRowContext(ctx, "SELECT 1").Scan(&one); assert.NoError(t, err) {
+			assert.Equal(t, 1, one)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package clickhouse_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_QueryTimeout(t *testing.T) {
+	const query = "SELECT sleepEachRow(0.5) FROM system.numbers LIMIT 10 SETTINGS max_block_size = 1"
+	queryErr := func(connect *sql.DB, timeout time.Duration) error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		rows, err := connect.QueryContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+		}
+		return rows.Err()
+	}
+	// the deadline is sent to the server as max_execution_time = 1
+	if connect, err := sql.Open("clickhouse", "tcp://127.0.0.1:9000?debug=true&deadline_margin=1"); assert.NoError(t, err) {
+		defer connect.Close()
+		err := queryErr(connect, 3*time.Second)
+		if assert.Error(t, err) {
+			assert.True(t, clickhouse.IsServerTimeout(err), err.Error())
+			assert.False(t, errors.Is(err, context.DeadlineExceeded))
+		}
+		var one int
+		if assert.NoError(t, connect.QueryRow("SELECT 1").Scan(&one)) {
+			assert.Equal(t, 1, one)
+		}
+	}
+	// the deadline is only enforced by the client
+	if connect, err := sql.Open("clickhouse", "tcp://127.0.0.1:9000?debug=true&deadline_margin=-1"); assert.NoError(t, err) {
+		defer connect.Close()
+		err := queryErr(connect, 1500*time.Millisecond)
+		if assert.Error(t, err) {
+			assert.True(t, errors.Is(err, context.DeadlineExceeded), err.Error())
+			assert.False(t, clickhouse.IsServerTimeout(err))
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package clickhouse_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_QueryAll(t *testing.T) {
+	type row struct {
+		Number  uint64     `ch:"number"`
+		Name    string     `ch:"name"`
+		Odd     *uint64    `ch:"odd"`
+		Tags    []string   `ch:"tags"`
+		Nested  [][]string `ch:"nested"`
+		Maybe   []*int32   `ch:"maybe"`
+		Comment *string    `ch:"comment"`
+	}
+	const query = `
+		SELECT
+			number
+			, toString(number)                        AS name
+			, if(number % 2 = 1, number, NULL)        AS odd
+			, ['a', toString(number)]                 AS tags
+			, [['a'], [], [toString(number)]]         AS nested
+			, [toInt32(number), NULL]                 AS maybe
+			, CAST(NULL AS Nullable(String))          AS comment
+		FROM system.numbers LIMIT ?
+	`
+	ctx := context.Background()
+	if connect, err := sql.Open("clickhouse", "tcp://127.0.0.1:9000?debug=true"); assert.NoError(t, err) {
+		if rows, err := clickhouse.QueryAll[row](ctx, connect, query, 3); assert.NoError(t, err) && assert.Len(t, rows, 3) {
+			assert.Equal(t, uint64(2), rows[2].Number)
+			assert.Equal(t, "2", rows[2].Name)
+			assert.Nil(t, rows[0].Odd)
+			if assert.NotNil(t, rows[1].Odd) {
+				assert.Equal(t, uint64(1), *rows[1].Odd)
+			}
+			assert.Equal(t, []string{"a", "2"}, rows[2].Tags)
+			assert.Equal(t, [][]string{{"a"}, {}, {"2"}}, rows[2].Nested)
+			if assert.Len(t, rows[2].Maybe, 2) && assert.NotNil(t, rows[2].Maybe[0]) {
+				assert.Equal(t, int32(2), *rows[2].Maybe[0])
+				assert.Nil(t, rows[2].Maybe[1])
+			}
+			assert.Nil(t, rows[2].Comment)
+		}
+		if rows, err := clickhouse.QueryAll[*row](ctx, connect, query, 2); assert.NoError(t, err) && assert.Len(t, rows, 2) {
+			assert.Equal(t, "1", rows[1].Name)
+		}
+		if numbers, err := clickhouse.QueryAll[uint64](ctx, connect, "SELECT number FROM system.numbers LIMIT 3"); assert.NoError(t, err) {
+			assert.Equal(t, []uint64{0, 1, 2}, numbers)
+		}
+		if values, err := clickhouse.QueryAll[*string](ctx, connect, "SELECT if(number = 1, NULL, toString(number)) FROM system.numbers LIMIT 2"); assert.NoError(t, err) && assert.Len(t, values, 2) {
+			assert.Nil(t, values[1])
+		}
+		_, err := clickhouse.QueryAll[uint64](ctx, connect, "SELECT 1, 2")
+		assert.Error(t, err)
+		_, err = clickhouse.QueryAll[[]int64](ctx, connect, "SELECT ['a']")
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "is scanned as []string")
+		}
+	}
+}
+
+func Test_QueryIter(t *testing.T) {
+	ctx := context.Background()
+	if connect, err := sql.Open("clickhouse", "tcp://127.0.0.1:9000?debug=true"); assert.NoError(t, err) {
+		iter, err := clickhouse.QueryIter[[]uint64](ctx, connect, "SELECT range(number) FROM system.numbers LIMIT 4")
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer iter.Close()
+		var lengths []int
+		for iter.Next() {
+			lengths = append(lengths, len(iter.Value()))
+		}
+		if assert.NoError(t, iter.Err()) {
+			assert.Equal(t, []int{0, 1, 2, 3}, lengths)
+		}
+	}
+}
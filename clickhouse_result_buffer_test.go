@@ -0,0 +1,55 @@
+package clickhouse_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ResultBuffer(t *testing.T) {
+	const query = "SELECT number, toString(number) FROM system.numbers LIMIT 100000 SETTINGS max_block_size = 1000"
+	if connect, err := sql.Open("clickhouse", "tcp://127.0.0.1:9000?debug=true&result_buffer_blocks=2&result_buffer_bytes=10000"); assert.NoError(t, err) {
+		defer connect.Close()
+		for _, ctx := range []context.Context{
+			context.Background(),
+			clickhouse.WithResultBuffer(context.Background(), clickhouse.ResultBuffer{Blocks: 1, Bytes: 1}),
+		} {
+			rows, err := connect.QueryContext(ctx, query)
+			if !assert.NoError(t, err) {
+				return
+			}
+			var (
+				count  int
+				sum    uint64
+				number uint64
+				name   string
+			)
+			for rows.Next() {
+				if count == 0 {
+					// let the receiver fill the buffer while the first block is not read
+					time.Sleep(100 * time.Millisecond)
+				}
+				if assert.NoError(t, rows.Scan(&number, &name)) {
+					count++
+					sum += number
+				}
+			}
+			if assert.NoError(t, rows.Err()) && assert.NoError(t, rows.Close()) {
+				assert.Equal(t, 100000, count)
+				assert.Equal(t, uint64(100000*99999/2), sum)
+				assert.Equal(t, int64(0), clickhouse.BufferedBytes())
+			}
+		}
+		// the blocks held back are released when the rows are closed early
+		rows, err := connect.Query(query)
+		if assert.NoError(t, err) && assert.True(t, rows.Next()) {
+			time.Sleep(100 * time.Millisecond)
+			assert.NoError(t, rows.Close())
+			assert.Equal(t, int64(0), clickhouse.BufferedBytes())
+		}
+	}
+}
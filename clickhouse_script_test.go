@@ -0,0 +1,79 @@
+package clickhouse_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ExecScript(t *testing.T) {
+	const script = `
+		DROP TABLE IF EXISTS clickhouse_test_script;
+		-- the table is created; and filled
+		CREATE TABLE clickhouse_test_script (
+			id    UInt8,
+			value String
+		) Engine=Memory;
+		INSERT INTO clickhouse_test_script VALUES (1, 'a;b'), (2, 'it''s; /* not a comment */');
+		INSERT INTO clickhouse_test_script SELECT 3, $$c;d$$;
+	`
+	ctx := context.Background()
+	if connect, err := sql.Open("clickhouse", "tcp://127.0.0.1:9000?debug=true"); assert.NoError(t, err) {
+		defer connect.Close()
+		conn, err := connect.Conn(ctx)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		err = conn.Raw(func(driverConn interface{}) error {
+			results, err := driverConn.(clickhouse.Clickhouse).ExecScript(ctx, script)
+			if err != nil {
+				return err
+			}
+			assert.Len(t, results, 4)
+			return nil
+		})
+		if !assert.NoError(t, err) {
+			return
+		}
+		var values []string
+		rows, err := conn.QueryContext(ctx, "SELECT value FROM clickhouse_test_script ORDER BY id")
+		if assert.NoError(t, err) {
+			for rows.Next() {
+				var value string
+				if assert.NoError(t, rows.Scan(&value)) {
+					values = append(values, value)
+				}
+			}
+			assert.NoError(t, rows.Close())
+			assert.Equal(t, []string{"a;b", "it's; /* not a comment */", "c;d"}, values)
+		}
+		err = conn.Raw(func(driverConn interface{}) error {
+			results, err := driverConn.(clickhouse.Clickhouse).ExecScript(ctx, `
+				TRUNCATE TABLE clickhouse_test_script;
+				SELECT * FROM clickhouse_test_script_missing;
+				DROP TABLE clickhouse_test_script;
+			`)
+			assert.Len(t, results, 1)
+			return err
+		})
+		var scriptErr *clickhouse.ScriptError
+		if assert.True(t, errors.As(err, &scriptErr)) {
+			assert.Equal(t, 1, scriptErr.Index)
+			assert.Equal(t, "SELECT * FROM clickhouse_test_script_missing", scriptErr.Statement)
+			var exception *clickhouse.Exception
+			assert.True(t, errors.As(err, &exception))
+		}
+		// the statements after the failed one are not executed
+		var count int
+		if assert.NoError(t, conn.QueryRowContext(ctx, "SELECT count() FROM clickhouse_test_script").Scan(&count)) {
+			assert.Equal(t, 0, count)
+		}
+		_, err = conn.ExecContext(ctx, "DROP TABLE clickhouse_test_script")
+		assert.NoError(t, err)
+	}
+}
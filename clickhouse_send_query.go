@@ -7,7 +7,12 @@ import (
 )
 
 func (ch *clickhouse) sendQuery(ctx context.Context, query string, externalTables []ExternalTable) error {
-	ch.logf("[send query] %s", query)
+	ch.logf("[send query] %s", ch.loggedQuery(ctx, query))
+	ctx = ch.withDeadline(ctx)
+	settings, err := ch.settings.withContext(ctx)
+	if err != nil {
+		return err
+	}
 	if err := ch.encoder.Uvarint(protocol.ClientQuery); err != nil {
 		return err
 	}
@@ -38,9 +43,9 @@ func (ch *clickhouse) sendQuery(ctx context.Context, query string, externalTable
 	}
 
 	// the settings are written as list of contiguous name-value pairs, finished with empty name
-	if !ch.settings.IsEmpty() {
-		ch.logf("[query settings] %s", ch.settings.settingsStr)
-		if err := ch.settings.Serialize(ch.encoder); err != nil {
+	if !settings.IsEmpty() {
+		ch.logf("[query settings] %s", settings.settingsStr)
+		if err := settings.Serialize(ch.encoder); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,74 @@
+package clickhouse_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func testSession(t *testing.T, session *clickhouse.Session) {
+	ctx := context.Background()
+	if !assert.NoError(t, session.Exec(ctx, "CREATE TEMPORARY TABLE clickhouse_test_session (id UInt64, name String)")) {
+		return
+	}
+	assert.Equal(t, []string{"clickhouse_test_session"}, session.TemporaryTables())
+	err := session.Batch(ctx, "INSERT INTO clickhouse_test_session (id, name)", func(batch clickhouse.Batch) error {
+		for i := 0; i < 10; i++ {
+			if err := batch.Append(uint64(i), "name"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, session.Exec(ctx, "INSERT INTO clickhouse_test_session SELECT number, 'other' FROM system.numbers LIMIT ?", 5))
+	var counts []uint64
+	err = session.Query(ctx, "SELECT count(), sum(id) FROM clickhouse_test_session WHERE name = ?", func(block *clickhouse.ResultBlock) error {
+		values, err := block.UInt64s(0)
+		counts = append(counts, values...)
+		return err
+	}, "name")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []uint64{10}, counts)
+	}
+	assert.NoError(t, session.Close())
+	assert.Empty(t, session.TemporaryTables())
+	assert.Equal(t, clickhouse.ErrSessionClosed, session.Exec(ctx, "SELECT 1"))
+}
+
+func Test_Session(t *testing.T) {
+	ctx := context.Background()
+	if connect, err := sql.Open("clickhouse", "tcp://127.0.0.1:9000?debug=true"); assert.NoError(t, err) {
+		defer connect.Close()
+		conn, err := connect.Conn(ctx)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		session, err := clickhouse.NewSession(conn)
+		if !assert.NoError(t, err) {
+			return
+		}
+		testSession(t, session)
+		// the temporary table was dropped with the session
+		var exists uint8
+		if assert.NoError(t, conn.QueryRowContext(ctx, "EXISTS TEMPORARY TABLE clickhouse_test_session").Scan(&exists)) {
+			assert.Equal(t, uint8(0), exists)
+		}
+	}
+}
+
+func Test_DirectSession(t *testing.T) {
+	if connect, err := clickhouse.OpenDirect("tcp://127.0.0.1:9000?debug=true"); assert.NoError(t, err) {
+		defer connect.Close()
+		session, err := clickhouse.NewDirectSession(connect)
+		if assert.NoError(t, err) {
+			testSession(t, session)
+		}
+	}
+}
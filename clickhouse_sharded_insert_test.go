@@ -0,0 +1,62 @@
+package clickhouse_test
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ShardedInsert(t *testing.T) {
+	const (
+		ddl = `
+			CREATE TABLE clickhouse_test_sharded_insert (
+				id   UInt64,
+				name String
+			) Engine=Memory
+		`
+	)
+	if connect, err := sql.Open("clickhouse", "tcp://127.0.0.1:9000?debug=true"); assert.NoError(t, err) {
+		if _, err := connect.Exec("DROP TABLE IF EXISTS clickhouse_test_sharded_insert"); assert.NoError(t, err) {
+			if _, err := connect.Exec(ddl); assert.NoError(t, err) {
+				// both shards are the same server, every row must be inserted once
+				insert, err := clickhouse.NewShardedInsert("INSERT INTO clickhouse_test_sharded_insert", clickhouse.ShardedInsertOptions{
+					DSN:    "tcp://127.0.0.1:9000?debug=true",
+					Shards: map[int][]string{1: {"127.0.0.1:9000"}, 2: {"127.0.0.1:9000"}},
+					Key:    clickhouse.CityHash64Key(1),
+					Loader: clickhouse.BulkLoaderOptions{Workers: 2},
+				})
+				if !assert.NoError(t, err) {
+					return
+				}
+				defer insert.Close()
+				var (
+					i     uint64
+					names = []string{"a", "b", "c", "d", "e"}
+				)
+				stats, err := insert.Load(context.Background(), func() ([]interface{}, error) {
+					if i == 100 {
+						return nil, io.EOF
+					}
+					i++
+					return []interface{}{i, names[i%5]}, nil
+				})
+				if !assert.NoError(t, err) || !assert.Len(t, stats, 2) {
+					return
+				}
+				// the first shard gets the rows with an even sharding key, as with a Distributed table
+				var count, first uint64
+				if err := connect.QueryRow(`
+					SELECT count(), countIf(cityHash64(name) % 2 = 0) FROM clickhouse_test_sharded_insert
+				`).Scan(&count, &first); assert.NoError(t, err) {
+					assert.Equal(t, uint64(100), count)
+					assert.Equal(t, first, stats[1].Rows)
+					assert.Equal(t, count-first, stats[2].Rows)
+				}
+			}
+		}
+	}
+}
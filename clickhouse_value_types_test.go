@@ -0,0 +1,92 @@
+package clickhouse_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type valueTypesUserID struct {
+	id uint64
+}
+
+func (u *valueTypesUserID) Scan(src interface{}) error {
+	id, ok := src.(uint64)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into valueTypesUserID", src)
+	}
+	u.id = id
+	return nil
+}
+
+func (u valueTypesUserID) Value() (driver.Value, error) {
+	return u.id, nil
+}
+
+func Test_ValueTypes(t *testing.T) {
+	const (
+		ddl = `
+			CREATE TABLE clickhouse_test_value_types (
+				ids   Array(UInt64),
+				names Array(Nullable(String))
+			) Engine=Memory
+		`
+		dml = `INSERT INTO clickhouse_test_value_types (ids, names) VALUES (?, ?)`
+	)
+	if connect, err := sql.Open("clickhouse", "tcp://127.0.0.1:9000?debug=true"); assert.NoError(t, err) {
+		if _, err := connect.Exec("DROP TABLE IF EXISTS clickhouse_test_value_types"); !assert.NoError(t, err) {
+			return
+		}
+		if _, err := connect.Exec(ddl); !assert.NoError(t, err) {
+			return
+		}
+		name := "a"
+		if tx, err := connect.Begin(); assert.NoError(t, err) {
+			if stmt, err := tx.Prepare(dml); assert.NoError(t, err) {
+				_, err := stmt.Exec(
+					clickhouse.ArrayOf[valueTypesUserID]{{1}, {2}},
+					clickhouse.ArrayOf[*string]{&name, nil},
+				)
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, tx.Commit())
+		}
+		var (
+			ids   clickhouse.ArrayOf[valueTypesUserID]
+			names clickhouse.ArrayOf[*string]
+		)
+		if err := connect.QueryRow("SELECT ids, names FROM clickhouse_test_value_types").Scan(&ids, &names); assert.NoError(t, err) {
+			assert.Equal(t, clickhouse.ArrayOf[valueTypesUserID]{{1}, {2}}, ids)
+			if assert.Len(t, names, 2) && assert.NotNil(t, names[0]) {
+				assert.Equal(t, "a", *names[0])
+				assert.Nil(t, names[1])
+			}
+		}
+		var count uint64
+		if err := connect.QueryRow("SELECT count() FROM system.numbers WHERE number IN (?) LIMIT 10", clickhouse.ArrayOf[valueTypesUserID]{{1}, {3}}).Scan(&count); assert.NoError(t, err) {
+			assert.Equal(t, uint64(2), count)
+		}
+		var tuple clickhouse.TupleValue
+		if err := connect.QueryRow("SELECT tuple(toUInt64(42), 'name')").Scan(&tuple); assert.NoError(t, err) {
+			var (
+				id   valueTypesUserID
+				name string
+			)
+			if assert.NoError(t, tuple.Unpack(&id, &name)) {
+				assert.Equal(t, uint64(42), id.id)
+				assert.Equal(t, "name", name)
+			}
+		}
+		var m clickhouse.MapValue[string, uint64]
+		if err := connect.QueryRow(
+			"SELECT arrayZip(mapKeys(m), mapValues(m)) FROM (SELECT map(?) AS m)",
+			clickhouse.MapValue[string, uint64]{"a": 1, "b": 2},
+		).Scan(&m); assert.NoError(t, err) {
+			assert.Equal(t, clickhouse.MapValue[string, uint64]{"a": 1, "b": 2}, m)
+		}
+	}
+}
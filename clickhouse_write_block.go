@@ -38,3 +38,19 @@ func (ch *clickhouse) writeBlock(block *data.Block, tableName string) error {
 	ch.encoder.SelectCompress(false)
 	return err
 }
+
+// writeEncodedBlock sends a block already encoded with data.Block.Write.
+func (ch *clickhouse) writeEncodedBlock(encoded []byte) error {
+	ch.Lock()
+	defer ch.Unlock()
+	if err := ch.encoder.Uvarint(protocol.ClientData); err != nil {
+		return err
+	}
+	if err := ch.encoder.String(""); err != nil {
+		return err
+	}
+	ch.encoder.SelectCompress(ch.compress)
+	_, err := ch.encoder.Write(encoded)
+	ch.encoder.SelectCompress(false)
+	return err
+}
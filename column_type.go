@@ -0,0 +1,279 @@
+package clickhouse
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ColumnType describes a ClickHouse data type as a tree, e.g. Array(Nullable(Decimal(18, 2))) is
+// an Array with a Nullable element with a Decimal element with the parameters 18 and 2.
+type ColumnType struct {
+	// Name is the name of the type without parameters: UInt64, DateTime64, Array, Tuple ...
+	Name string
+	// Field is the name of the element in a named Tuple or in Nested, empty otherwise.
+	Field string
+	// Params are the parameters which are not types, e.g. the length of FixedString,
+	// the precision and scale of Decimal or the function of SimpleAggregateFunction.
+	Params []string
+	// Elements are the nested types: the element of Array, Nullable and LowCardinality,
+	// the elements of Tuple and Nested, the key and value of Map and the arguments of aggregate functions.
+	Elements []*ColumnType
+	// Timezone is the time zone of DateTime and DateTime64, empty when the server time zone is used.
+	Timezone string
+	// EnumValues are the values of Enum8 and Enum16 in the order of the declaration.
+	EnumValues []EnumValue
+}
+
+// EnumValue is a value of an Enum8 or Enum16 type.
+type EnumValue struct {
+	Name  string
+	Value int16
+}
+
+// ParseColumnType parses a ClickHouse type as returned by DatabaseTypeName.
+func ParseColumnType(chType string) (*ColumnType, error) {
+	typ, err := parseColumnType(strings.TrimSpace(chType))
+	if err != nil {
+		return nil, fmt.Errorf("clickhouse: invalid type %q: %w", chType, err)
+	}
+	return typ, nil
+}
+
+// String formats the type as ClickHouse does.
+func (typ *ColumnType) String() string {
+	var args []string
+	args = append(args, typ.Params...)
+	for _, element := range typ.Elements {
+		if element.Field != "" {
+			args = append(args, element.Field+" "+element.String())
+			continue
+		}
+		args = append(args, element.String())
+	}
+	if typ.Timezone != "" {
+		args = append(args, quoteTypeString(typ.Timezone))
+	}
+	for _, value := range typ.EnumValues {
+		args = append(args, quoteTypeString(value.Name)+" = "+strconv.Itoa(int(value.Value)))
+	}
+	if len(args) == 0 {
+		return typ.Name
+	}
+	return typ.Name + "(" + strings.Join(args, ", ") + ")"
+}
+
+// Nullable reports whether the type is Nullable, also within LowCardinality.
+func (typ *ColumnType) Nullable() bool {
+	switch typ.Name {
+	case "Nullable":
+		return true
+	case "LowCardinality":
+		return len(typ.Elements) == 1 && typ.Elements[0].Nullable()
+	}
+	return false
+}
+
+func parseColumnType(chType string) (*ColumnType, error) {
+	open := strings.IndexByte(chType, '(')
+	if open == -1 {
+		if !isTypeName(chType) {
+			return nil, fmt.Errorf("unexpected %q", chType)
+		}
+		return &ColumnType{Name: chType}, nil
+	}
+	if !strings.HasSuffix(chType, ")") {
+		return nil, fmt.Errorf("unbalanced parentheses")
+	}
+	typ := &ColumnType{
+		Name: strings.TrimSpace(chType[:open]),
+	}
+	if !isTypeName(typ.Name) {
+		return nil, fmt.Errorf("unexpected %q", typ.Name)
+	}
+	args, err := splitTypeArgs(chType[open+1 : len(chType)-1])
+	if err != nil {
+		return nil, err
+	}
+	switch typ.Name {
+	case "Array", "Nullable", "LowCardinality":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%s has %d arguments, expected 1", typ.Name, len(args))
+		}
+		err = typ.parseElements(args, false)
+	case "Map":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("Map has %d arguments, expected 2", len(args))
+		}
+		err = typ.parseElements(args, false)
+	case "Tuple", "Nested":
+		err = typ.parseElements(args, true)
+	case "SimpleAggregateFunction", "AggregateFunction":
+		if len(args) == 0 {
+			return nil, fmt.Errorf("%s has no function", typ.Name)
+		}
+		typ.Params = args[:1]
+		err = typ.parseElements(args[1:], false)
+	case "DateTime":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("DateTime has %d arguments, expected 1", len(args))
+		}
+		typ.Timezone, err = unquoteTypeString(args[0])
+	case "DateTime64":
+		if len(args) < 1 || len(args) > 2 {
+			return nil, fmt.Errorf("DateTime64 has %d arguments, expected 1 or 2", len(args))
+		}
+		typ.Params = args[:1]
+		if len(args) == 2 {
+			typ.Timezone, err = unquoteTypeString(args[1])
+		}
+	case "Enum8", "Enum16":
+		err = typ.parseEnumValues(args)
+	default:
+		typ.Params = args
+	}
+	if err != nil {
+		return nil, err
+	}
+	return typ, nil
+}
+
+func (typ *ColumnType) parseElements(args []string, named bool) error {
+	for _, arg := range args {
+		var field string
+		if named {
+			field, arg = splitTypeField(arg)
+		}
+		element, err := parseColumnType(arg)
+		if err != nil {
+			return err
+		}
+		element.Field = field
+		typ.Elements = append(typ.Elements, element)
+	}
+	return nil
+}
+
+func (typ *ColumnType) parseEnumValues(args []string) error {
+	for _, arg := range args {
+		end := quotedStringEnd(arg)
+		if end == -1 {
+			return fmt.Errorf("invalid Enum value %s", arg)
+		}
+		name, err := unquoteTypeString(arg[:end])
+		if err != nil {
+			return err
+		}
+		value := strings.TrimSpace(arg[end:])
+		if !strings.HasPrefix(value, "=") {
+			return fmt.Errorf("invalid Enum value %s", arg)
+		}
+		number, err := strconv.ParseInt(strings.TrimSpace(value[1:]), 10, 16)
+		if err != nil {
+			return fmt.Errorf("invalid Enum value %s", arg)
+		}
+		typ.EnumValues = append(typ.EnumValues, EnumValue{Name: name, Value: int16(number)})
+	}
+	return nil
+}
+
+// splitTypeArgs splits the arguments of a type by the commas which are not within parentheses or quotes.
+func splitTypeArgs(args string) ([]string, error) {
+	var (
+		result []string
+		depth  int
+		last   int
+	)
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case '\'', '`':
+			end := quotedStringEnd(args[i:])
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated quote")
+			}
+			i += end - 1
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth < 0 {
+				return nil, fmt.Errorf("unbalanced parentheses")
+			}
+		case ',':
+			if depth == 0 {
+				result = append(result, strings.TrimSpace(args[last:i]))
+				last = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced parentheses")
+	}
+	if arg := strings.TrimSpace(args[last:]); arg != "" || len(result) != 0 {
+		result = append(result, arg)
+	}
+	return result, nil
+}
+
+// splitTypeField splits the element of a named Tuple into the name and the type.
+func splitTypeField(arg string) (field, chType string) {
+	if strings.HasPrefix(arg, "`") {
+		if end := quotedStringEnd(arg); end != -1 && end < len(arg) && arg[end] == ' ' {
+			return arg[:end], strings.TrimSpace(arg[end:])
+		}
+		return "", arg
+	}
+	if space := strings.IndexByte(arg, ' '); space != -1 && isTypeName(arg[:space]) {
+		return arg[:space], strings.TrimSpace(arg[space:])
+	}
+	return "", arg
+}
+
+// quotedStringEnd returns the index after the closing quote of the string starting with a quote, -1 if it is not terminated.
+func quotedStringEnd(str string) int {
+	if len(str) == 0 {
+		return -1
+	}
+	quote := str[0]
+	for i := 1; i < len(str); i++ {
+		switch str[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		}
+	}
+	return -1
+}
+
+func unquoteTypeString(str string) (string, error) {
+	if len(str) < 2 || str[0] != '\'' || quotedStringEnd(str) != len(str) {
+		return "", fmt.Errorf("expected a quoted string, got %s", str)
+	}
+	var unquoted strings.Builder
+	for i := 1; i < len(str)-1; i++ {
+		if str[i] == '\\' {
+			i++
+		}
+		unquoted.WriteByte(str[i])
+	}
+	return unquoted.String(), nil
+}
+
+func quoteTypeString(str string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(str) + "'"
+}
+
+func isTypeName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z':
+		case '0' <= r && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
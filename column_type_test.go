@@ -0,0 +1,90 @@
+package clickhouse
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/lib/column"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseColumnType(t *testing.T) {
+	for chType, expected := range map[string]*ColumnType{
+		"UInt64":                    {Name: "UInt64"},
+		"FixedString(16)":           {Name: "FixedString", Params: []string{"16"}},
+		"Decimal(18, 2)":            {Name: "Decimal", Params: []string{"18", "2"}},
+		"DateTime('Europe/Moscow')": {Name: "DateTime", Timezone: "Europe/Moscow"},
+		"DateTime64(3, 'UTC')":      {Name: "DateTime64", Params: []string{"3"}, Timezone: "UTC"},
+		"Array(Nullable(String))": {Name: "Array", Elements: []*ColumnType{
+			{Name: "Nullable", Elements: []*ColumnType{{Name: "String"}}},
+		}},
+		"Enum8('a' = 1, 'b, \\'c\\'' = -2)": {Name: "Enum8", EnumValues: []EnumValue{{Name: "a", Value: 1}, {Name: "b, 'c'", Value: -2}}},
+		"Tuple(Int32, Array(Tuple(String, DateTime)))": {Name: "Tuple", Elements: []*ColumnType{
+			{Name: "Int32"},
+			{Name: "Array", Elements: []*ColumnType{
+				{Name: "Tuple", Elements: []*ColumnType{{Name: "String"}, {Name: "DateTime"}}},
+			}},
+		}},
+		"Tuple(id UInt64, `full name` LowCardinality(String))": {Name: "Tuple", Elements: []*ColumnType{
+			{Name: "UInt64", Field: "id"},
+			{Name: "LowCardinality", Field: "`full name`", Elements: []*ColumnType{{Name: "String"}}},
+		}},
+		"Map(String, Array(UInt8))": {Name: "Map", Elements: []*ColumnType{
+			{Name: "String"},
+			{Name: "Array", Elements: []*ColumnType{{Name: "UInt8"}}},
+		}},
+		"SimpleAggregateFunction(anyLast, Nullable(Float64))": {Name: "SimpleAggregateFunction", Params: []string{"anyLast"}, Elements: []*ColumnType{
+			{Name: "Nullable", Elements: []*ColumnType{{Name: "Float64"}}},
+		}},
+		"AggregateFunction(quantiles(0.5, 0.9), UInt64)": {Name: "AggregateFunction", Params: []string{"quantiles(0.5, 0.9)"}, Elements: []*ColumnType{
+			{Name: "UInt64"},
+		}},
+	} {
+		typ, err := ParseColumnType(chType)
+		if assert.NoError(t, err, chType) {
+			assert.Equal(t, expected, typ, chType)
+			assert.Equal(t, chType, typ.String())
+		}
+	}
+	for _, chType := range []string{
+		"", "Array(String", "Array(String))", "Array()", "Array(String, UInt8)", "Map(String)",
+		"DateTime(UTC)", "Enum8('a')", "Enum8('a' = x)", "Enum8('a = 1)", "UInt 64",
+	} {
+		_, err := ParseColumnType(chType)
+		assert.Error(t, err, chType)
+	}
+}
+
+func Test_ColumnTypeNullable(t *testing.T) {
+	for chType, nullable := range map[string]bool{
+		"Nullable(String)":                 true,
+		"LowCardinality(Nullable(String))": true,
+		"LowCardinality(String)":           false,
+		"Array(Nullable(String))":          false,
+	} {
+		typ, err := ParseColumnType(chType)
+		if assert.NoError(t, err) {
+			assert.Equal(t, nullable, typ.Nullable(), chType)
+		}
+	}
+}
+
+func Test_RowsColumnTypeLength(t *testing.T) {
+	rows := &rows{}
+	for _, chType := range []string{"String", "FixedString(16)", "Nullable(FixedString(2))", "UInt64", "Array(String)"} {
+		col, err := column.Factory("c", chType, time.UTC)
+		if !assert.NoError(t, err) {
+			return
+		}
+		rows.blockColumns = append(rows.blockColumns, col)
+	}
+	for idx, expected := range []struct {
+		length int64
+		ok     bool
+	}{{math.MaxInt64, true}, {16, true}, {2, true}, {0, false}, {0, false}} {
+		length, ok := rows.ColumnTypeLength(idx)
+		assert.Equal(t, expected.length, length, idx)
+		assert.Equal(t, expected.ok, ok, idx)
+	}
+}
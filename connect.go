@@ -48,8 +48,9 @@ func dial(options connOptions) (*connect, error) {
 			}
 			return v
 		}
-		conn  net.Conn
-		ident = abs(int(atomic.AddInt32(&tick, 1)))
+		conn    net.Conn
+		rawConn net.Conn
+		ident   = abs(int(atomic.AddInt32(&tick, 1)))
 	)
 	tlsConfig := options.tlsConfig
 	if options.secure {
@@ -76,16 +77,10 @@ func dial(options connOptions) (*connect, error) {
 		}
 		switch {
 		case options.secure:
-			conn, err = tls.DialWithDialer(
-				&net.Dialer{
-					Timeout: options.connTimeout,
-				},
-				"tcp",
-				options.hosts[num],
-				tlsConfig,
-			)
+			rawConn, conn, err = dialTLS(options.hosts[num], options.connTimeout, tlsConfig)
 		default:
 			conn, err = net.DialTimeout("tcp", options.hosts[num], options.connTimeout)
+			rawConn = conn
 		}
 		if err == nil {
 			options.logf(
@@ -97,7 +92,7 @@ func dial(options connOptions) (*connect, error) {
 				num,
 				conn.RemoteAddr(),
 			)
-			if tcp, ok := conn.(*net.TCPConn); ok {
+			if tcp, ok := rawConn.(*net.TCPConn); ok {
 				err = tcp.SetNoDelay(options.noDelay) // Disable or enable the Nagle Algorithm for this tcp socket
 				if err != nil {
 					return nil, err
@@ -105,6 +100,7 @@ func dial(options connOptions) (*connect, error) {
 			}
 			return &connect{
 				Conn:         conn,
+				rawConn:      rawConn,
 				logf:         options.logf,
 				ident:        ident,
 				buffer:       bufio.NewReader(conn),
@@ -126,8 +122,36 @@ func dial(options connOptions) (*connect, error) {
 	return nil, err
 }
 
+// dialTLS works like tls.DialWithDialer but keeps hold of the underlying
+// TCP connection, which is needed to check the liveness of pooled connections.
+func dialTLS(addr string, timeout time.Duration, config *tls.Config) (net.Conn, *tls.Conn, error) {
+	rawConn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	if config.ServerName == "" {
+		config = config.Clone()
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			config.ServerName = host
+		} else {
+			config.ServerName = addr
+		}
+	}
+	conn := tls.Client(rawConn, config)
+	if timeout != 0 {
+		conn.SetDeadline(time.Now().Add(timeout))
+	}
+	if err := conn.Handshake(); err != nil {
+		rawConn.Close()
+		return nil, nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	return rawConn, conn, nil
+}
+
 type connect struct {
 	net.Conn
+	rawConn               net.Conn
 	logf                  func(string, ...interface{})
 	ident                 int
 	buffer                *bufio.Reader
@@ -136,6 +160,12 @@ type connect struct {
 	writeTimeout          time.Duration
 	lastReadDeadlineTime  time.Time
 	lastWriteDeadlineTime time.Time
+	lastActivity          time.Time
+}
+
+// idle returns how long the connection has not been used for reading or writing.
+func (conn *connect) idle() time.Duration {
+	return now().Sub(conn.lastActivity)
 }
 
 func (conn *connect) Read(b []byte) (int, error) {
@@ -145,10 +175,12 @@ func (conn *connect) Read(b []byte) (int, error) {
 		total  int
 		dstLen = len(b)
 	)
-	if currentTime := now(); conn.readTimeout != 0 && currentTime.Sub(conn.lastReadDeadlineTime) > (conn.readTimeout>>2) {
+	currentTime := now()
+	if conn.readTimeout != 0 && currentTime.Sub(conn.lastReadDeadlineTime) > (conn.readTimeout>>2) {
 		conn.SetReadDeadline(time.Now().Add(conn.readTimeout))
 		conn.lastReadDeadlineTime = currentTime
 	}
+	conn.lastActivity = currentTime
 	for total < dstLen {
 		if n, err = conn.buffer.Read(b[total:]); err != nil {
 			conn.logf("[connect] read error: %v", err)
@@ -167,10 +199,12 @@ func (conn *connect) Write(b []byte) (int, error) {
 		total  int
 		srcLen = len(b)
 	)
-	if currentTime := now(); conn.writeTimeout != 0 && currentTime.Sub(conn.lastWriteDeadlineTime) > (conn.writeTimeout>>2) {
+	currentTime := now()
+	if conn.writeTimeout != 0 && currentTime.Sub(conn.lastWriteDeadlineTime) > (conn.writeTimeout>>2) {
 		conn.SetWriteDeadline(time.Now().Add(conn.writeTimeout))
 		conn.lastWriteDeadlineTime = currentTime
 	}
+	conn.lastActivity = currentTime
 	for total < srcLen {
 		if n, err = conn.Conn.Write(b[total:]); err != nil {
 			conn.logf("[connect] write error: %v", err)
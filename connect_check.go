@@ -15,7 +15,12 @@ var errUnexpectedRead = errors.New("unexpected read from socket")
 func (conn *connect) connCheck() error {
 	var sysErr error
 
-	sysConn, ok := conn.Conn.(syscall.Conn)
+	if conn.buffer.Buffered() != 0 {
+		return errUnexpectedRead
+	}
+	// For secure connections conn.Conn is a *tls.Conn which does not expose
+	// the file descriptor, so the check is done against the underlying TCP connection.
+	sysConn, ok := conn.rawConn.(syscall.Conn)
 	if !ok {
 		return nil
 	}
@@ -2,8 +2,16 @@ package clickhouse
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"database/sql"
 	"database/sql/driver"
+	"math/big"
+	"net"
 	"testing"
 	"time"
 
@@ -83,3 +91,63 @@ func Test_ConnCheckNegative(t *testing.T) {
 		}
 	}
 }
+
+func Test_ConnCheckSecure(t *testing.T) {
+	cert, err := selfSignedCertificate()
+	if !assert.NoError(t, err) {
+		return
+	}
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer listener.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			conn.(*tls.Conn).Handshake()
+		}
+		accepted <- conn
+	}()
+	conn, err := dial(connOptions{
+		secure:       true,
+		skipVerify:   true,
+		hosts:        []string{listener.Addr().String()},
+		connTimeout:  time.Second,
+		openStrategy: connOpenInOrder,
+		logf:         func(string, ...interface{}) {},
+	})
+	if assert.NoError(t, err) {
+		defer conn.Close()
+		server := <-accepted
+		if assert.NotNil(t, server) {
+			assert.NoError(t, conn.connCheck())
+			server.Close()
+			time.Sleep(100 * time.Millisecond)
+			assert.Error(t, conn.connCheck())
+		}
+	}
+}
+
+func selfSignedCertificate() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+	}, nil
+}
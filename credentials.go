@@ -0,0 +1,175 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Credentials used to authenticate a connection.
+type Credentials struct {
+	Username string
+	Password string
+	// Expires is the time after which short-lived credentials (e.g. tokens)
+	// must not be used for new connections. Zero means they never expire.
+	Expires time.Time
+}
+
+// CredentialsProvider is called every time a new connection is opened,
+// so rotated credentials are picked up without reopening the sql.DB.
+type CredentialsProvider interface {
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// CredentialsProviderFunc is an adapter to allow the use of ordinary functions as CredentialsProvider.
+type CredentialsProviderFunc func(ctx context.Context) (Credentials, error)
+
+// Credentials calls f(ctx).
+func (f CredentialsProviderFunc) Credentials(ctx context.Context) (Credentials, error) {
+	return f(ctx)
+}
+
+// EnvCredentials reads the username and password from environment variables.
+// An empty or unset username variable falls back to the default username.
+type EnvCredentials struct {
+	UsernameVar string
+	PasswordVar string
+}
+
+func (env EnvCredentials) Credentials(context.Context) (Credentials, error) {
+	var credentials Credentials
+	if env.UsernameVar != "" {
+		credentials.Username = os.Getenv(env.UsernameVar)
+	}
+	if env.PasswordVar != "" {
+		password, ok := os.LookupEnv(env.PasswordVar)
+		if !ok {
+			return Credentials{}, fmt.Errorf("credentials: environment variable %s is not set", env.PasswordVar)
+		}
+		credentials.Password = password
+	}
+	return credentials, nil
+}
+
+// FileCredentials reads the username and password from files, e.g. mounted secrets.
+// The files are read on every call, trailing new lines are ignored.
+type FileCredentials struct {
+	UsernameFile string
+	PasswordFile string
+}
+
+func (files FileCredentials) Credentials(context.Context) (Credentials, error) {
+	var (
+		err         error
+		credentials Credentials
+	)
+	if files.UsernameFile != "" {
+		if credentials.Username, err = readSecret(files.UsernameFile); err != nil {
+			return Credentials{}, err
+		}
+	}
+	if files.PasswordFile != "" {
+		if credentials.Password, err = readSecret(files.PasswordFile); err != nil {
+			return Credentials{}, err
+		}
+	}
+	return credentials, nil
+}
+
+func readSecret(name string) (string, error) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return "", fmt.Errorf("credentials: %v", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// CacheCredentials wraps a provider of short-lived credentials and reuses
+// them until refreshBefore is left until they expire.
+func CacheCredentials(provider CredentialsProvider, refreshBefore time.Duration) CredentialsProvider {
+	return &cachedCredentials{
+		provider:      provider,
+		refreshBefore: refreshBefore,
+	}
+}
+
+type cachedCredentials struct {
+	mutex         sync.Mutex
+	provider      CredentialsProvider
+	refreshBefore time.Duration
+	credentials   *Credentials
+}
+
+func (cache *cachedCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if cache.credentials != nil && !cache.credentials.Expires.IsZero() && time.Until(cache.credentials.Expires) > cache.refreshBefore {
+		return *cache.credentials, nil
+	}
+	credentials, err := cache.provider.Credentials(ctx)
+	if err != nil {
+		return Credentials{}, err
+	}
+	cache.credentials = &credentials
+	return credentials, nil
+}
+
+var (
+	credentialsProvidersLock sync.RWMutex
+	credentialsProviders     map[string]CredentialsProvider
+)
+
+// RegisterCredentialsProvider registers a CredentialsProvider to be used with sql.Open
+// by passing credentials_provider=key in the DSN.
+func RegisterCredentialsProvider(key string, provider CredentialsProvider) error {
+	credentialsProvidersLock.Lock()
+	if credentialsProviders == nil {
+		credentialsProviders = make(map[string]CredentialsProvider)
+	}
+	credentialsProviders[key] = provider
+	credentialsProvidersLock.Unlock()
+	return nil
+}
+
+// DeregisterCredentialsProvider removes the CredentialsProvider associated with key.
+func DeregisterCredentialsProvider(key string) {
+	credentialsProvidersLock.Lock()
+	if credentialsProviders != nil {
+		delete(credentialsProviders, key)
+	}
+	credentialsProvidersLock.Unlock()
+}
+
+func getCredentialsProvider(key string) (provider CredentialsProvider) {
+	credentialsProvidersLock.RLock()
+	provider = credentialsProviders[key]
+	credentialsProvidersLock.RUnlock()
+	return
+}
+
+// staticCredentials are the username and password given in the DSN.
+type staticCredentials Credentials
+
+func (static staticCredentials) Credentials(context.Context) (Credentials, error) {
+	return Credentials(static), nil
+}
+
+// credentials returns the username and password for a new connection,
+// an empty username falls back to the one from the DSN.
+func (ch *clickhouse) credentials(ctx context.Context) (string, string, error) {
+	credentials, err := ch.auth.Credentials(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	if !credentials.Expires.IsZero() && time.Now().After(credentials.Expires) {
+		return "", "", fmt.Errorf("credentials: expired at %s", credentials.Expires)
+	}
+	if len(credentials.Username) == 0 {
+		credentials.Username = ch.username
+	}
+	return credentials.Username, credentials.Password, nil
+}
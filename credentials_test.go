@@ -0,0 +1,80 @@
+package clickhouse
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_EnvCredentials(t *testing.T) {
+	os.Setenv("CLICKHOUSE_TEST_USERNAME", "user")
+	os.Setenv("CLICKHOUSE_TEST_PASSWORD", "qwerty")
+	defer os.Unsetenv("CLICKHOUSE_TEST_USERNAME")
+	defer os.Unsetenv("CLICKHOUSE_TEST_PASSWORD")
+	credentials, err := EnvCredentials{
+		UsernameVar: "CLICKHOUSE_TEST_USERNAME",
+		PasswordVar: "CLICKHOUSE_TEST_PASSWORD",
+	}.Credentials(context.Background())
+	if assert.NoError(t, err) {
+		assert.Equal(t, Credentials{Username: "user", Password: "qwerty"}, credentials)
+	}
+	_, err = EnvCredentials{PasswordVar: "CLICKHOUSE_TEST_NOT_SET"}.Credentials(context.Background())
+	assert.Error(t, err)
+}
+
+func Test_FileCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "clickhouse_credentials")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	var (
+		provider = FileCredentials{PasswordFile: filepath.Join(dir, "password")}
+		ctx      = context.Background()
+	)
+	_, err = provider.Credentials(ctx)
+	assert.Error(t, err)
+	for _, password := range []string{"qwerty", "rotated"} {
+		assert.NoError(t, ioutil.WriteFile(provider.PasswordFile, []byte(password+"\n"), 0600))
+		if credentials, err := provider.Credentials(ctx); assert.NoError(t, err) {
+			assert.Equal(t, password, credentials.Password)
+		}
+	}
+}
+
+func Test_CacheCredentials(t *testing.T) {
+	var (
+		calls    int
+		expires  = time.Now().Add(30 * time.Second)
+		provider = CacheCredentials(CredentialsProviderFunc(func(context.Context) (Credentials, error) {
+			calls++
+			return Credentials{Password: "token", Expires: expires}, nil
+		}), time.Minute)
+		ctx = context.Background()
+	)
+	// expires within refreshBefore, so a new token is requested every time
+	provider.Credentials(ctx)
+	provider.Credentials(ctx)
+	assert.Equal(t, 2, calls)
+	expires = time.Now().Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if credentials, err := provider.Credentials(ctx); assert.NoError(t, err) {
+			assert.Equal(t, "token", credentials.Password)
+		}
+	}
+	assert.Equal(t, 3, calls)
+}
+
+func Test_CredentialsProviderDSN(t *testing.T) {
+	_, err := open("tcp://127.0.0.1:9000?credentials_provider=not_registered")
+	assert.EqualError(t, err, "invalid credentials_provider - no provider registered under name not_registered")
+	_, err = open("tcp://127.0.0.1:9000?username_file=/run/secrets/username")
+	assert.EqualError(t, err, "username_file is given without password_file")
+	_, err = open("tcp://127.0.0.1:9000?username_env=CH_USERNAME&password_file=/run/secrets/password")
+	assert.EqualError(t, err, "username_env is given without password_env")
+}
@@ -1,6 +1,6 @@
 module github.com/ClickHouse/clickhouse-go
 
-go 1.16
+go 1.18
 
 require (
 	github.com/bkaradzic/go-lz4 v1.0.0
@@ -9,5 +9,10 @@ require (
 	github.com/pierrec/lz4 v2.0.5+incompatible
 	github.com/shopspring/decimal v1.3.1
 	github.com/stretchr/testify v1.3.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
 	google.golang.org/appengine v1.6.7 // indirect
 )
@@ -120,6 +120,17 @@ func isInsert(query string) bool {
 	return false
 }
 
+// isReadOnly reports whether the query only reads data and therefore can be safely retried.
+func isReadOnly(query string) bool {
+	if f := strings.Fields(query); len(f) != 0 {
+		switch strings.ToUpper(strings.TrimLeft(f[0], "(")) {
+		case "SELECT", "WITH", "SHOW", "DESC", "DESCRIBE", "EXISTS":
+			return true
+		}
+	}
+	return false
+}
+
 func quote(v driver.Value) string {
 	switch v := reflect.ValueOf(v); v.Kind() {
 	case reflect.Slice:
@@ -63,3 +63,21 @@ func Test_Quote(t *testing.T) {
 		assert.Equal(t, expected, quote(value))
 	}
 }
+
+func Test_IsReadOnly(t *testing.T) {
+	for query, expected := range map[string]bool{
+		"SELECT 1":                           true,
+		"  select * FROM example":            true,
+		"WITH 1 AS x SELECT x":               true,
+		"(SELECT 1) UNION ALL (SELECT 2)":    true,
+		"SHOW TABLES":                        true,
+		"DESCRIBE TABLE example":             true,
+		"INSERT INTO example VALUES (?)":     false,
+		"INSERT INTO example SELECT 1":       false,
+		"CREATE TABLE example (a UInt8)":     false,
+		"ALTER TABLE example DELETE WHERE 1": false,
+		"":                                   false,
+	} {
+		assert.Equal(t, expected, isReadOnly(query), query)
+	}
+}
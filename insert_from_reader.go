@@ -0,0 +1,569 @@
+package clickhouse
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ClickHouse/clickhouse-go/lib/column"
+)
+
+// InputFormat is the format of the data read by InsertFromReader.
+type InputFormat string
+
+const (
+	// CSV rows with the values in the order of the table columns.
+	FormatCSV InputFormat = "CSV"
+	// CSV rows with a header line naming the columns.
+	FormatCSVWithNames InputFormat = "CSVWithNames"
+	// Tab separated rows with the values in the order of the table columns.
+	FormatTSV InputFormat = "TSV"
+	// Tab separated rows with a header line naming the columns.
+	FormatTSVWithNames InputFormat = "TSVWithNames"
+	// One JSON object per line, the columns are taken from the keys of the first object.
+	FormatJSONEachRow InputFormat = "JSONEachRow"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported input format")
+
+// InputError is returned by InsertFromReader for a value that cannot be parsed or inserted.
+type InputError struct {
+	Line   int    // line number of the row, starting at 1
+	Column string // column name, empty if the error is not related to a single column
+	Err    error
+}
+
+func (e *InputError) Error() string {
+	if len(e.Column) == 0 {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %d: column %s: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *InputError) Unwrap() error {
+	return e.Err
+}
+
+// InsertFromReader parses the data from r client-side, converts the values using the
+// types of the table columns and sends them to the server in blocks, flushed by the
+// block_size, max_block_bytes and max_block_age limits.
+//
+// NULL values are written as \N in CSV and TSV and as null in JSONEachRow. Arrays are
+// written as literals, e.g. [1,2,'a'], in CSV and TSV and as JSON arrays in JSONEachRow.
+// Columns missing from the header or from the first JSON object get their default values
+// on the server. The keys missing from a later JSON object, and the null values of columns
+// which are not Nullable, get the zero value of the column type (NULL for Nullable columns).
+// Tuple columns are not supported.
+//
+// An error stops the insert: the rows which have not been flushed yet are discarded,
+// blocks already sent to the server are persisted.
+func (ch *clickhouse) InsertFromReader(ctx context.Context, table string, format InputFormat, r io.Reader) error {
+	reader := &inputReader{
+		reader: bufio.NewReader(r),
+		line:   1,
+	}
+	switch format {
+	case FormatCSV, FormatCSVWithNames:
+		reader.readFields = reader.readCSV
+	case FormatTSV, FormatTSVWithNames:
+		reader.readFields = reader.readTSV
+	case FormatJSONEachRow:
+		return ch.insertJSONEachRow(ctx, table, reader)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+	query := "INSERT INTO " + table
+	if format == FormatCSVWithNames || format == FormatTSVWithNames {
+		header, err := reader.readFields()
+		switch {
+		case err == io.EOF:
+			return nil
+		case err != nil:
+			return &InputError{Line: reader.start, Err: err}
+		}
+		names := make([]string, 0, len(header))
+		for _, field := range header {
+			names = append(names, field.text)
+		}
+		query += " (" + quoteIdentifiers(names) + ")"
+	}
+	b, err := ch.PrepareBatch(ctx, query)
+	if err != nil {
+		return err
+	}
+	columns := ch.batch.block.Columns
+	for {
+		fields, err := reader.readFields()
+		line := reader.start
+		switch {
+		case err == io.EOF:
+			return b.Send()
+		case err != nil:
+			b.Abort()
+			return &InputError{Line: line, Err: err}
+		case len(fields) != len(columns):
+			b.Abort()
+			return &InputError{Line: line, Err: fmt.Errorf("expected %d values, got %d", len(columns), len(fields))}
+		}
+		row := make([]interface{}, len(columns))
+		for i, field := range fields {
+			if row[i], err = parseText(columns[i], field); err != nil {
+				b.Abort()
+				return &InputError{Line: line, Column: columns[i].Name(), Err: err}
+			}
+		}
+		if err := b.Append(row...); err != nil {
+			return ch.inputError(b, line, err)
+		}
+	}
+}
+
+func (ch *clickhouse) insertJSONEachRow(ctx context.Context, table string, reader *inputReader) error {
+	var (
+		b       Batch
+		columns []column.Column
+	)
+	for {
+		object, err := reader.readJSON()
+		line := reader.start
+		switch {
+		case err == io.EOF:
+			if b == nil {
+				return nil
+			}
+			return b.Send()
+		case err != nil:
+			if b != nil {
+				b.Abort()
+			}
+			return &InputError{Line: line, Err: err}
+		}
+		if b == nil {
+			names := make([]string, 0, len(object))
+			for name := range object {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			if b, err = ch.PrepareBatch(ctx, "INSERT INTO "+table+" ("+quoteIdentifiers(names)+")"); err != nil {
+				return err
+			}
+			columns = ch.batch.block.Columns
+		}
+		row, inputErr := jsonRow(columns, object)
+		if inputErr != nil {
+			b.Abort()
+			inputErr.Line = line
+			return inputErr
+		}
+		if err := b.Append(row...); err != nil {
+			return ch.inputError(b, line, err)
+		}
+	}
+}
+
+// jsonRow returns the values of the columns in the object, the keys missing in the object
+// get the default value of the column (column.DefaultValue: NULL for Nullable, the zero value otherwise).
+func jsonRow(columns []column.Column, object map[string]interface{}) ([]interface{}, *InputError) {
+	row := make([]interface{}, len(columns))
+	for i, col := range columns {
+		value, found := object[col.Name()]
+		if !found {
+			row[i] = column.DefaultValue(col)
+			continue
+		}
+		var err error
+		if row[i], err = parseJSON(col, value); err != nil {
+			return nil, &InputError{Column: col.Name(), Err: err}
+		}
+		delete(object, col.Name())
+	}
+	for name := range object {
+		return nil, &InputError{Column: name, Err: errors.New("unknown column")}
+	}
+	return row, nil
+}
+
+// inputError adds the line number to an error of Batch.Append.
+func (ch *clickhouse) inputError(b Batch, line int, err error) error {
+	if e, ok := err.(*column.ErrUnexpectedType); ok {
+		err = &InputError{Line: line, Column: e.Column.Name(), Err: err}
+	} else {
+		err = &InputError{Line: line, Err: err}
+	}
+	if ch.batch == b {
+		// the batch has not been released by a failed flush
+		b.Abort()
+	}
+	return err
+}
+
+func quoteIdentifiers(names []string) string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, "`"+strings.NewReplacer(`\`, `\\`, "`", "\\`").Replace(name)+"`")
+	}
+	return strings.Join(quoted, ", ")
+}
+
+type inputField struct {
+	text   string
+	quoted bool
+}
+
+type inputReader struct {
+	reader     *bufio.Reader
+	line       int // current line
+	start      int // line the last record started at
+	readFields func() ([]inputField, error)
+}
+
+func (r *inputReader) readRune() (rune, error) {
+	char, _, err := r.reader.ReadRune()
+	if char == '\n' {
+		r.line++
+	}
+	return char, err
+}
+
+// readCSV reads a record, the fields can be quoted with double or single quotes
+// and a quote is escaped by doubling it.
+func (r *inputReader) readCSV() ([]inputField, error) {
+	var (
+		fields []inputField
+		field  strings.Builder
+		quoted bool
+	)
+	for {
+		if len(fields) == 0 && field.Len() == 0 && !quoted {
+			r.start = r.line
+		}
+		char, err := r.readRune()
+		switch {
+		case err == io.EOF && len(fields) == 0 && field.Len() == 0 && !quoted:
+			return nil, io.EOF
+		case err == io.EOF:
+			return append(fields, inputField{text: field.String(), quoted: quoted}), nil
+		case err != nil:
+			return nil, err
+		case (char == '"' || char == '\'') && field.Len() == 0 && !quoted:
+			if err := r.readQuoted(&field, char); err != nil {
+				return nil, err
+			}
+			quoted = true
+		case char == ',':
+			fields = append(fields, inputField{text: field.String(), quoted: quoted})
+			field.Reset()
+			quoted = false
+		case char == '\n':
+			text := strings.TrimSuffix(field.String(), "\r")
+			if len(fields) == 0 && len(text) == 0 && !quoted {
+				// skip empty lines
+				field.Reset()
+				continue
+			}
+			return append(fields, inputField{text: text, quoted: quoted}), nil
+		case quoted:
+			if char != '\r' {
+				return nil, fmt.Errorf("unexpected %q after the closing quote", char)
+			}
+		default:
+			field.WriteRune(char)
+		}
+	}
+}
+
+func (r *inputReader) readQuoted(field *strings.Builder, quote rune) error {
+	for {
+		char, err := r.readRune()
+		switch {
+		case err == io.EOF:
+			return errors.New("unterminated quoted field")
+		case err != nil:
+			return err
+		case char == quote:
+			next, _, err := r.reader.ReadRune()
+			if err == nil && next == quote {
+				field.WriteRune(quote)
+				continue
+			}
+			if err == nil {
+				r.reader.UnreadRune()
+			}
+			return nil
+		default:
+			field.WriteRune(char)
+		}
+	}
+}
+
+var tsvUnescaper = strings.NewReplacer(`\b`, "\b", `\f`, "\f", `\r`, "\r", `\n`, "\n", `\t`, "\t", `\0`, "\x00", `\'`, "'", `\\`, `\`)
+
+// readTSV reads a line of tab separated fields, special characters are escaped with a backslash.
+func (r *inputReader) readTSV() ([]inputField, error) {
+	for {
+		r.start = r.line
+		text, err := r.reader.ReadString('\n')
+		switch {
+		case err == io.EOF && len(text) == 0:
+			return nil, io.EOF
+		case err != nil && err != io.EOF:
+			return nil, err
+		}
+		if err == nil {
+			r.line++
+		}
+		if text = strings.TrimSuffix(strings.TrimSuffix(text, "\n"), "\r"); len(text) == 0 {
+			// skip empty lines
+			continue
+		}
+		var fields []inputField
+		for _, field := range strings.Split(text, "\t") {
+			if field == `\N` {
+				fields = append(fields, inputField{text: field})
+			} else {
+				fields = append(fields, inputField{text: tsvUnescaper.Replace(field), quoted: true})
+			}
+		}
+		return fields, nil
+	}
+}
+
+// readJSON reads a JSON object from a single line.
+func (r *inputReader) readJSON() (map[string]interface{}, error) {
+	for {
+		r.start = r.line
+		text, err := r.reader.ReadBytes('\n')
+		switch {
+		case err == io.EOF && len(bytes.TrimSpace(text)) == 0:
+			return nil, io.EOF
+		case err != nil && err != io.EOF:
+			return nil, err
+		}
+		if err == nil {
+			r.line++
+		}
+		if text = bytes.TrimSpace(text); len(text) == 0 {
+			continue
+		}
+		var (
+			object  map[string]interface{}
+			decoder = json.NewDecoder(bytes.NewReader(text))
+		)
+		decoder.UseNumber()
+		if err := decoder.Decode(&object); err != nil {
+			return nil, err
+		}
+		return object, nil
+	}
+}
+
+// parseText converts a CSV or TSV field to a value accepted by the Write method of the column.
+func parseText(col column.Column, field inputField) (interface{}, error) {
+	if null, ok := col.(*column.Nullable); ok {
+		if !field.quoted && (field.text == `\N` || field.text == "NULL") {
+			return nil, nil
+		}
+		col = null.GetColumn()
+	}
+	if array, ok := col.(*column.Array); ok {
+		value, rest, err := parseArray(array.GetColumn(), array.Depth(), strings.TrimSpace(field.text))
+		if err != nil {
+			return nil, err
+		}
+		if len(strings.TrimSpace(rest)) != 0 {
+			return nil, fmt.Errorf("unexpected %q after the array", rest)
+		}
+		return value, nil
+	}
+	return parseScalar(col, field.text)
+}
+
+func parseScalar(col column.Column, text string) (interface{}, error) {
+	switch col.(type) {
+	case *column.Int8:
+		v, err := strconv.ParseInt(text, 10, 8)
+		return int8(v), err
+	case *column.Int16:
+		v, err := strconv.ParseInt(text, 10, 16)
+		return int16(v), err
+	case *column.Int32:
+		v, err := strconv.ParseInt(text, 10, 32)
+		return int32(v), err
+	case *column.Int64:
+		return strconv.ParseInt(text, 10, 64)
+	case *column.UInt8:
+		switch text {
+		case "true":
+			return uint8(1), nil
+		case "false":
+			return uint8(0), nil
+		}
+		v, err := strconv.ParseUint(text, 10, 8)
+		return uint8(v), err
+	case *column.UInt16:
+		v, err := strconv.ParseUint(text, 10, 16)
+		return uint16(v), err
+	case *column.UInt32:
+		v, err := strconv.ParseUint(text, 10, 32)
+		return uint32(v), err
+	case *column.UInt64:
+		return strconv.ParseUint(text, 10, 64)
+	case *column.Float32:
+		v, err := strconv.ParseFloat(text, 32)
+		return float32(v), err
+	case *column.Float64:
+		return strconv.ParseFloat(text, 64)
+	case *column.Tuple:
+		return nil, fmt.Errorf("%s columns are not supported", col.CHType())
+	}
+	// String, FixedString, Enum, Date, DateTime, DateTime64, Decimal, UUID, IPv4 and IPv6 parse strings
+	return text, nil
+}
+
+// parseArray parses an array literal, e.g. [1, 2, 'a\'b', NULL], returning the rest of the text.
+func parseArray(col column.Column, depth int, text string) (interface{}, string, error) {
+	if !strings.HasPrefix(text, "[") {
+		return nil, text, fmt.Errorf("expected array, got %q", text)
+	}
+	var (
+		values = reflect.MakeSlice(arrayType(depth), 0, 0)
+		rest   = strings.TrimLeft(text[1:], " ")
+	)
+	if strings.HasPrefix(rest, "]") {
+		return values.Interface(), rest[1:], nil
+	}
+	for {
+		var (
+			value interface{}
+			err   error
+		)
+		rest = strings.TrimLeft(rest, " ")
+		switch {
+		case depth > 1:
+			value, rest, err = parseArray(col, depth-1, rest)
+		case strings.HasPrefix(rest, "'") || strings.HasPrefix(rest, `"`):
+			var str string
+			if str, rest, err = unquoteString(rest); err == nil {
+				value, err = parseText(col, inputField{text: str, quoted: true})
+			}
+		default:
+			end := strings.IndexAny(rest, ",]")
+			if end < 0 {
+				return nil, rest, errors.New("unterminated array")
+			}
+			value, err = parseText(col, inputField{text: strings.TrimSpace(rest[:end])})
+			rest = rest[end:]
+		}
+		if err != nil {
+			return nil, rest, err
+		}
+		if value == nil {
+			values = reflect.Append(values, reflect.Zero(values.Type().Elem()))
+		} else {
+			values = reflect.Append(values, reflect.ValueOf(value))
+		}
+		switch rest = strings.TrimLeft(rest, " "); {
+		case strings.HasPrefix(rest, ","):
+			rest = rest[1:]
+		case strings.HasPrefix(rest, "]"):
+			return values.Interface(), rest[1:], nil
+		default:
+			return nil, rest, errors.New("unterminated array")
+		}
+	}
+}
+
+// arrayType returns []interface{} for the innermost level and nested slices for the outer ones.
+func arrayType(depth int) reflect.Type {
+	t := reflect.TypeOf([]interface{}{})
+	for i := 1; i < depth; i++ {
+		t = reflect.SliceOf(t)
+	}
+	return t
+}
+
+func unquoteString(text string) (string, string, error) {
+	var (
+		str   strings.Builder
+		quote = text[0]
+	)
+	for i := 1; i < len(text); i++ {
+		switch char := text[i]; {
+		case char == '\\' && i+1 < len(text):
+			i++
+			str.WriteString(tsvUnescaper.Replace(text[i-1 : i+1]))
+		case char == quote:
+			return str.String(), text[i+1:], nil
+		default:
+			str.WriteByte(char)
+		}
+	}
+	return "", "", errors.New("unterminated quoted string")
+}
+
+// parseJSON converts a JSON value to a value accepted by the Write method of the column.
+// A null value of a column which is not Nullable gets the default value of the column, as with the server.
+func parseJSON(col column.Column, value interface{}) (interface{}, error) {
+	if null, ok := col.(*column.Nullable); ok {
+		if value == nil {
+			return nil, nil
+		}
+		col = null.GetColumn()
+	}
+	switch v := value.(type) {
+	case nil:
+		return column.DefaultValue(col), nil
+	case string:
+		if _, ok := col.(*column.Array); ok {
+			return parseText(col, inputField{text: v})
+		}
+		return parseScalar(col, v)
+	case json.Number:
+		return parseScalar(col, v.String())
+	case bool:
+		return parseScalar(col, strconv.FormatBool(v))
+	case []interface{}:
+		array, ok := col.(*column.Array)
+		if !ok {
+			return nil, fmt.Errorf("unexpected array for %s", col.CHType())
+		}
+		return parseJSONArray(array.GetColumn(), array.Depth(), v)
+	}
+	return nil, fmt.Errorf("unexpected %T value for %s", value, col.CHType())
+}
+
+func parseJSONArray(col column.Column, depth int, array []interface{}) (interface{}, error) {
+	values := reflect.MakeSlice(arrayType(depth), 0, len(array))
+	for _, elem := range array {
+		var (
+			value interface{}
+			err   error
+		)
+		if depth > 1 {
+			nested, ok := elem.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("expected nested array, got %T", elem)
+			}
+			value, err = parseJSONArray(col, depth-1, nested)
+		} else {
+			value, err = parseJSON(col, elem)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			values = reflect.Append(values, reflect.Zero(values.Type().Elem()))
+		} else {
+			values = reflect.Append(values, reflect.ValueOf(value))
+		}
+	}
+	return values.Interface(), nil
+}
@@ -0,0 +1,207 @@
+package clickhouse
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/lib/column"
+	"github.com/stretchr/testify/assert"
+)
+
+func readAll(t *testing.T, reader *inputReader) ([][]inputField, []int) {
+	var (
+		records [][]inputField
+		lines   []int
+	)
+	for {
+		fields, err := reader.readFields()
+		if err == io.EOF {
+			return records, lines
+		}
+		if !assert.NoError(t, err) {
+			return records, lines
+		}
+		records = append(records, fields)
+		lines = append(lines, reader.start)
+	}
+}
+
+func Test_ReadCSV(t *testing.T) {
+	reader := &inputReader{
+		reader: newBufioReader("a,\"b,\"\"c\"\"\",'d'\r\n\n1,\"multi\nline\",\\N\n2,,NULL"),
+		line:   1,
+	}
+	reader.readFields = reader.readCSV
+	records, lines := readAll(t, reader)
+	assert.Equal(t, [][]inputField{
+		{{text: "a"}, {text: `b,"c"`, quoted: true}, {text: "d", quoted: true}},
+		{{text: "1"}, {text: "multi\nline", quoted: true}, {text: `\N`}},
+		{{text: "2"}, {text: ""}, {text: "NULL"}},
+	}, records)
+	assert.Equal(t, []int{1, 3, 5}, lines)
+
+	reader = &inputReader{reader: newBufioReader("a,\"b\"c\n"), line: 1}
+	_, err := reader.readCSV()
+	assert.Error(t, err)
+	reader = &inputReader{reader: newBufioReader("a,\"b\nc\n"), line: 1}
+	_, err = reader.readCSV()
+	assert.Error(t, err)
+}
+
+func Test_ReadTSV(t *testing.T) {
+	reader := &inputReader{
+		reader: newBufioReader("a\tb\\tc\t\\N\n\n1\tline\\nbreak\t\\\\N\n"),
+		line:   1,
+	}
+	reader.readFields = reader.readTSV
+	records, lines := readAll(t, reader)
+	assert.Equal(t, [][]inputField{
+		{{text: "a", quoted: true}, {text: "b\tc", quoted: true}, {text: `\N`}},
+		{{text: "1", quoted: true}, {text: "line\nbreak", quoted: true}, {text: `\N`, quoted: true}},
+	}, records)
+	assert.Equal(t, []int{1, 3}, lines)
+}
+
+func Test_ParseText(t *testing.T) {
+	factory := func(chType string) column.Column {
+		col, err := column.Factory("c", chType, time.UTC)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return col
+	}
+	for _, test := range []struct {
+		chType   string
+		field    inputField
+		expected interface{}
+	}{
+		{"Int8", inputField{text: "-8"}, int8(-8)},
+		{"UInt16", inputField{text: "16"}, uint16(16)},
+		{"UInt8", inputField{text: "true"}, uint8(1)},
+		{"Int64", inputField{text: "64"}, int64(64)},
+		{"Float32", inputField{text: "1.5"}, float32(1.5)},
+		{"String", inputField{text: `\N`}, `\N`},
+		{"Date", inputField{text: "2021-06-01"}, "2021-06-01"},
+		{"Nullable(String)", inputField{text: `\N`}, nil},
+		{"Nullable(String)", inputField{text: "NULL", quoted: true}, "NULL"},
+		{"Nullable(Int32)", inputField{text: "32"}, int32(32)},
+		{"Array(UInt8)", inputField{text: "[]"}, []interface{}{}},
+		{"Array(UInt8)", inputField{text: "[1, 2,3]"}, []interface{}{uint8(1), uint8(2), uint8(3)}},
+		{"Array(String)", inputField{text: `['a,b', 'c\'d', "e"]`}, []interface{}{"a,b", "c'd", "e"}},
+		{"Array(Nullable(String))", inputField{text: `['NULL', NULL]`}, []interface{}{"NULL", nil}},
+		{"Array(Array(Int16))", inputField{text: "[[1], [], [2, 3]]"}, [][]interface{}{{int16(1)}, {}, {int16(2), int16(3)}}},
+	} {
+		value, err := parseText(factory(test.chType), test.field)
+		if assert.NoError(t, err, test.chType) {
+			assert.Equal(t, test.expected, value, test.chType)
+		}
+	}
+	for chType, text := range map[string]string{
+		"Int8":                 "256",
+		"UInt32":               "-1",
+		"Float64":              "x",
+		"Array(UInt8)":         "[1, 2",
+		"Array(String)":        "['a']]",
+		"Tuple(UInt8, String)": "(1, 'a')",
+	} {
+		_, err := parseText(factory(chType), inputField{text: text})
+		assert.Error(t, err, chType)
+	}
+}
+
+func Test_ParseJSON(t *testing.T) {
+	reader := &inputReader{
+		reader: newBufioReader(`{"id": 1, "name": "a", "tags": ["x", null], "ok": true, "nested": [[1], []]}` + "\n\n{\"id\": 2}"),
+		line:   1,
+	}
+	object, err := reader.readJSON()
+	if !assert.NoError(t, err) {
+		return
+	}
+	for chType, test := range map[string]struct {
+		key      string
+		expected interface{}
+	}{
+		"UInt64":                  {"id", uint64(1)},
+		"String":                  {"id", "1"},
+		"UInt8":                   {"ok", uint8(1)},
+		"Array(Nullable(String))": {"tags", []interface{}{"x", nil}},
+		"Array(Array(Int32))":     {"nested", [][]interface{}{{int32(1)}, {}}},
+		"Nullable(String)":        {"missing", nil},
+	} {
+		col, err := column.Factory("c", chType, time.UTC)
+		if assert.NoError(t, err) {
+			value, err := parseJSON(col, object[test.key])
+			if assert.NoError(t, err, chType) {
+				assert.Equal(t, test.expected, value, chType)
+			}
+		}
+	}
+	if _, err := reader.readJSON(); assert.NoError(t, err) {
+		assert.Equal(t, 3, reader.start)
+	}
+	_, err = reader.readJSON()
+	assert.Equal(t, io.EOF, err)
+
+	for chType, expected := range map[string]interface{}{"String": "", "UInt64": uint64(0), "Array(String)": []string{}} {
+		col, _ := column.Factory("c", chType, time.UTC)
+		if value, err := parseJSON(col, nil); assert.NoError(t, err, chType) {
+			assert.Equal(t, expected, value, "null gets the default value of %s", chType)
+		}
+	}
+}
+
+func Test_JSONRowMissingKey(t *testing.T) {
+	reader := &inputReader{
+		reader: newBufioReader(`{"id": 1, "name": "a", "comment": "b"}` + "\n" + `{"id": 2}` + "\n" + `{"id": 3, "other": 1}`),
+		line:   1,
+	}
+	var columns []column.Column
+	for name, chType := range map[string]string{"id": "UInt64", "name": "String", "comment": "Nullable(String)"} {
+		col, err := column.Factory(name, chType, time.UTC)
+		if !assert.NoError(t, err) {
+			return
+		}
+		columns = append(columns, col)
+	}
+	expected := map[string][]interface{}{
+		"id":      {uint64(1), uint64(2)},
+		"name":    {"a", ""},
+		"comment": {"b", nil},
+	}
+	for i := 0; i < 2; i++ {
+		object, err := reader.readJSON()
+		if !assert.NoError(t, err) {
+			return
+		}
+		row, inputErr := jsonRow(columns, object)
+		if assert.Nil(t, inputErr) {
+			for c, col := range columns {
+				assert.Equal(t, expected[col.Name()][i], row[c], col.Name())
+			}
+		}
+	}
+	object, err := reader.readJSON()
+	if assert.NoError(t, err) {
+		_, inputErr := jsonRow(columns, object)
+		if assert.NotNil(t, inputErr) {
+			assert.Equal(t, "other", inputErr.Column)
+		}
+	}
+}
+
+func Test_InputError(t *testing.T) {
+	assert.Equal(t, "line 3: column id: invalid syntax", (&InputError{Line: 3, Column: "id", Err: errorString("invalid syntax")}).Error())
+	assert.Equal(t, "line 1: expected 2 values, got 1", (&InputError{Line: 1, Err: errorString("expected 2 values, got 1")}).Error())
+}
+
+type errorString string
+
+func (e errorString) Error() string { return string(e) }
+
+func newBufioReader(s string) *bufio.Reader {
+	return bufio.NewReader(strings.NewReader(s))
+}
@@ -461,3 +461,30 @@ func Test_RawString(t *testing.T) {
 		}
 	}
 }
+
+func Test_BytesRead(t *testing.T) {
+	var (
+		buf     bytes.Buffer
+		encoder = NewEncoder(&buf)
+	)
+	encoder.UInt8(1)
+	encoder.UInt16(2)
+	encoder.UInt32(3)
+	encoder.UInt64(4)
+	encoder.String("value")
+	encoder.Write(make([]byte, 16))
+	encoder.Write([]byte{1, 2, 3})
+	decoder := NewDecoder(&buf)
+	decoder.UInt8()
+	decoder.UInt16()
+	decoder.UInt32()
+	decoder.UInt64()
+	if assert.Equal(t, int64(15), decoder.BytesRead()) {
+		decoder.String()
+		assert.Equal(t, int64(21), decoder.BytesRead())
+		decoder.Decimal128()
+		assert.Equal(t, int64(37), decoder.BytesRead())
+		decoder.ReadFull(make([]byte, 3))
+		assert.Equal(t, int64(40), decoder.BytesRead())
+	}
+}
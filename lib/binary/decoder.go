@@ -24,12 +24,18 @@ type Decoder struct {
 	input         io.Reader
 	compressInput io.Reader
 	scratch       [binary.MaxVarintLen64]byte
+	read          int64
 }
 
 func (decoder *Decoder) SelectCompress(compress bool) {
 	decoder.compress = compress
 }
 
+// BytesRead returns the number of bytes decoded so far, after decompression.
+func (decoder *Decoder) BytesRead() int64 {
+	return decoder.read
+}
+
 func (decoder *Decoder) Get() io.Reader {
 	if decoder.compress && decoder.compressInput != nil {
 		return decoder.compressInput
@@ -93,6 +99,7 @@ func (decoder *Decoder) UInt16() (uint16, error) {
 	if _, err := decoder.Get().Read(decoder.scratch[:2]); err != nil {
 		return 0, err
 	}
+	decoder.read += 2
 	return uint16(decoder.scratch[0]) | uint16(decoder.scratch[1])<<8, nil
 }
 
@@ -100,6 +107,7 @@ func (decoder *Decoder) UInt32() (uint32, error) {
 	if _, err := decoder.Get().Read(decoder.scratch[:4]); err != nil {
 		return 0, err
 	}
+	decoder.read += 4
 	return uint32(decoder.scratch[0]) |
 		uint32(decoder.scratch[1])<<8 |
 		uint32(decoder.scratch[2])<<16 |
@@ -110,6 +118,7 @@ func (decoder *Decoder) UInt64() (uint64, error) {
 	if _, err := decoder.Get().Read(decoder.scratch[:8]); err != nil {
 		return 0, err
 	}
+	decoder.read += 8
 	return uint64(decoder.scratch[0]) |
 		uint64(decoder.scratch[1])<<8 |
 		uint64(decoder.scratch[2])<<16 |
@@ -137,6 +146,7 @@ func (decoder *Decoder) Float64() (float64, error) {
 }
 
 func (decoder *Decoder) Fixed(ln int) ([]byte, error) {
+	decoder.read += int64(ln)
 	if reader, ok := decoder.Get().(FixedReader); ok {
 		return reader.Fixed(ln)
 	}
@@ -147,6 +157,13 @@ func (decoder *Decoder) Fixed(ln int) ([]byte, error) {
 	return buf, nil
 }
 
+// ReadFull reads exactly len(buf) bytes into buf.
+func (decoder *Decoder) ReadFull(buf []byte) error {
+	n, err := io.ReadFull(decoder.Get(), buf)
+	decoder.read += int64(n)
+	return err
+}
+
 func (decoder *Decoder) String() (string, error) {
 	strlen, err := decoder.Uvarint()
 	if err != nil {
@@ -162,6 +179,7 @@ func (decoder *Decoder) String() (string, error) {
 func (decoder *Decoder) Decimal128() ([]byte, error) {
 	bytes := make([]byte, 16)
 	_, err := decoder.Get().Read(bytes)
+	decoder.read += 16
 	return bytes, err
 }
 
@@ -169,6 +187,7 @@ func (decoder *Decoder) ReadByte() (byte, error) {
 	if _, err := decoder.Get().Read(decoder.scratch[:1]); err != nil {
 		return 0x0, err
 	}
+	decoder.read++
 	return decoder.scratch[0], nil
 }
 
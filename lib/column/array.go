@@ -25,6 +25,15 @@ type Array struct {
 	nullable bool
 }
 
+// ScanType returns the type of the values, a slice nested as deep as the array.
+func (array *Array) ScanType() reflect.Type {
+	t := array.base.ScanType()
+	for i := 1; i < array.depth; i++ {
+		t = reflect.SliceOf(t)
+	}
+	return t
+}
+
 func (array *Array) Read(decoder *binary.Decoder, isNull bool) (interface{}, error) {
 	return nil, fmt.Errorf("do not use Read method for Array(T) column")
 }
@@ -168,6 +177,11 @@ func (array *Array) arrayType(level int) reflect.Type {
 	return t
 }
 
+// GetColumn returns the column of the array elements, for nested arrays the innermost one.
+func (array *Array) GetColumn() Column {
+	return array.column
+}
+
 func (array *Array) Depth() int {
 	return array.depth
 }
@@ -1,6 +1,7 @@
 package column
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"math"
@@ -280,3 +281,76 @@ func Benchmark_Column_IP_STRING(b *testing.B) {
 		}
 	}
 }
+
+// encodeBenchmarkColumn returns rows values of the column encoded as in a block.
+func encodeBenchmarkColumn(b *testing.B, chType string, rows int, value interface{}) (Column, []byte) {
+	column, err := Factory("", chType, time.UTC)
+	if err != nil {
+		b.Fatal(err)
+	}
+	var buf bytes.Buffer
+	encoder := binary.NewEncoder(&buf)
+	for i := 0; i < rows; i++ {
+		if err := column.Write(encoder, value); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return column, buf.Bytes()
+}
+
+// benchmarkRead decodes a block of the column value by value, boxing every value.
+func benchmarkRead(b *testing.B, chType string, value interface{}) {
+	const rows = 1000
+	column, data := encodeBenchmarkColumn(b, chType, rows, value)
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var (
+			decoder = binary.NewDecoder(bytes.NewReader(data))
+			values  = make([]interface{}, 0, rows)
+		)
+		for row := 0; row < rows; row++ {
+			v, err := column.Read(decoder, false)
+			if err != nil {
+				b.Fatal(err)
+			}
+			values = append(values, v)
+		}
+	}
+}
+
+// benchmarkReadValues decodes a block of the column into typed values.
+func benchmarkReadValues(b *testing.B, chType string, value interface{}) {
+	const rows = 1000
+	column, data := encodeBenchmarkColumn(b, chType, rows, value)
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ReadValues(column, binary.NewDecoder(bytes.NewReader(data)), rows); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_Column_Read_Int32(b *testing.B) { benchmarkRead(b, "Int32", int32(math.MaxInt32)) }
+func Benchmark_Column_ReadValues_Int32(b *testing.B) {
+	benchmarkReadValues(b, "Int32", int32(math.MaxInt32))
+}
+
+func Benchmark_Column_Read_UInt64(b *testing.B) { benchmarkRead(b, "UInt64", uint64(math.MaxUint64)) }
+func Benchmark_Column_ReadValues_UInt64(b *testing.B) {
+	benchmarkReadValues(b, "UInt64", uint64(math.MaxUint64))
+}
+
+func Benchmark_Column_Read_Float64(b *testing.B)       { benchmarkRead(b, "Float64", math.Pi) }
+func Benchmark_Column_ReadValues_Float64(b *testing.B) { benchmarkReadValues(b, "Float64", math.Pi) }
+
+func Benchmark_Column_Read_String(b *testing.B)       { benchmarkRead(b, "String", "value") }
+func Benchmark_Column_ReadValues_String(b *testing.B) { benchmarkReadValues(b, "String", "value") }
+
+func Benchmark_Column_Read_DateTime(b *testing.B) { benchmarkRead(b, "DateTime", time.Now()) }
+func Benchmark_Column_ReadValues_DateTime(b *testing.B) {
+	benchmarkReadValues(b, "DateTime", time.Now())
+}
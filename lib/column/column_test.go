@@ -745,3 +745,111 @@ func Test_Column_NullableEnum8(t *testing.T) {
 		}
 	}
 }
+
+func Test_Column_DefaultValue(t *testing.T) {
+	var (
+		buf     bytes.Buffer
+		encoder = binary.NewEncoder(&buf)
+	)
+	for _, chType := range []string{
+		"Int8", "UInt64", "Float32", "String", "FixedString(2)", "Date", "DateTime", "DateTime64(3)",
+		"UUID", "IPv4", "IPv6", "Enum8('a'=1,'b'=2)", "Decimal(18,2)",
+	} {
+		if column, err := columns.Factory("column_name", chType, time.UTC); assert.NoError(t, err, chType) {
+			assert.NoError(t, column.Write(encoder, columns.DefaultValue(column)), chType)
+		}
+	}
+	// arrays are written by the block, element by element
+	for chType, expected := range map[string]interface{}{
+		"Array(String)":          []string{},
+		"Array(Nullable(Int32))": []*int32{},
+	} {
+		if column, err := columns.Factory("column_name", chType, time.UTC); assert.NoError(t, err) {
+			assert.Equal(t, expected, columns.DefaultValue(column), chType)
+		}
+	}
+	if column, err := columns.Factory("column_name", "Nullable(String)", time.UTC); assert.NoError(t, err) {
+		assert.Nil(t, columns.DefaultValue(column))
+	}
+}
+
+func Test_Column_ReadValues(t *testing.T) {
+	now := time.Unix(time.Now().Unix(), 0)
+	for chType, values := range map[string][]interface{}{
+		"Int8":                 {int8(-1), int8(1)},
+		"Int16":                {int16(-1), int16(1)},
+		"Int32":                {int32(-1), int32(1)},
+		"Int64":                {int64(-1), int64(1)},
+		"UInt8":                {uint8(1), uint8(255)},
+		"UInt16":               {uint16(1), uint16(65535)},
+		"UInt32":               {uint32(1), uint32(4294967295)},
+		"UInt64":               {uint64(1), uint64(18446744073709551615)},
+		"Float32":              {float32(1.5), float32(-2.5)},
+		"Float64":              {1.5, -2.5},
+		"String":               {"", "value", "другое значение"},
+		"FixedString(3)":       {"abc", "de\x00"},
+		"Date":                 {time.Date(2021, 6, 1, 0, 0, 0, 0, time.Local), time.Date(1970, 1, 1, 0, 0, 0, 0, time.Local)},
+		"DateTime":             {now, now.Add(time.Hour)},
+		"Enum8('a'=1,'b'=2)":   {"a", "b"},
+		"UUID":                 {"123e4567-e89b-12d3-a456-426655440000"},
+		"Decimal(9,2)":         {"1.25", "2.5"},
+		"DateTime64(3, 'UTC')": {now.UTC()},
+	} {
+		var (
+			buf     bytes.Buffer
+			encoder = binary.NewEncoder(&buf)
+		)
+		column, err := columns.Factory("column_name", chType, time.Local)
+		if !assert.NoError(t, err, chType) {
+			continue
+		}
+		for _, value := range values {
+			assert.NoError(t, column.Write(encoder, value), chType)
+		}
+		decoded, err := columns.ReadValues(column, binary.NewDecoder(&buf), len(values))
+		if assert.NoError(t, err, chType) && assert.Equal(t, len(values), decoded.Len(), chType) {
+			for row, value := range values {
+				if expected, ok := value.(time.Time); ok {
+					assert.True(t, expected.Equal(decoded.Value(row).(time.Time)), chType)
+					continue
+				}
+				assert.Equal(t, value, decoded.Value(row), chType)
+			}
+		}
+		_, err = columns.ReadValues(column, binary.NewDecoder(&buf), 1)
+		assert.Error(t, err, "%s: no data", chType)
+	}
+}
+
+func Test_Column_StringValues(t *testing.T) {
+	var (
+		buf     bytes.Buffer
+		encoder = binary.NewEncoder(&buf)
+		strings = make([]string, 5000)
+	)
+	for i := range strings {
+		strings[i] = fmt.Sprintf("value %d", i)
+		assert.NoError(t, encoder.String(strings[i]))
+	}
+	column, _ := columns.Factory("column_name", "String", time.Local)
+	if values, err := columns.ReadValues(column, binary.NewDecoder(&buf), len(strings)); assert.NoError(t, err) {
+		if values, ok := values.(*columns.StringValues); assert.True(t, ok) {
+			assert.Equal(t, strings, values.Strings())
+			assert.Equal(t, "value 42", values.String(42))
+		}
+	}
+}
+
+func Test_Column_ArrayScanType(t *testing.T) {
+	for chType, expected := range map[string]interface{}{
+		"Array(String)":                  []string{},
+		"Array(Nullable(Int32))":         []*int32{},
+		"Array(Array(String))":           [][]string{},
+		"Array(Array(Array(Float64)))":   [][][]float64{},
+		"Array(Array(Nullable(UInt64)))": [][]*uint64{},
+	} {
+		if column, err := columns.Factory("column_name", chType, time.UTC); assert.NoError(t, err) {
+			assert.Equal(t, reflect.TypeOf(expected), column.ScanType(), chType)
+		}
+	}
+}
@@ -106,6 +106,12 @@ func (base *base) defaultValue() interface{} {
 	return base.valueOf.Interface()
 }
 
+// DefaultValue returns the value inserted for a column which has no value in a row:
+// the zero value of the type, NULL for Nullable and an empty array for Array.
+func DefaultValue(column Column) interface{} {
+	return column.defaultValue()
+}
+
 func (base *base) String() string {
 	return fmt.Sprintf("%s (%s)", base.name, base.chType)
 }
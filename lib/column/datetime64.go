@@ -90,6 +90,11 @@ func (dt *DateTime64) parse(value string) (int64, error) {
 	return tv.UnixNano(), nil
 }
 
+// GetPrecision returns the number of digits of the sub-second part of the values.
+func (dt *DateTime64) GetPrecision() (int, error) {
+	return dt.getPrecision()
+}
+
 func (dt *DateTime64) getPrecision() (int, error) {
 	dtParams := dt.base.chType[11 : len(dt.base.chType)-1]
 	precision, err := strconv.Atoi(strings.Split(dtParams, ",")[0])
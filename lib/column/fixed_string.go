@@ -69,3 +69,8 @@ func parseFixedString(name, chType string) (*FixedString, error) {
 		len: strLen,
 	}, nil
 }
+
+// GetLength returns the length of the values in bytes.
+func (str *FixedString) GetLength() int {
+	return str.len
+}
@@ -17,6 +17,10 @@ func (null *Nullable) ScanType() reflect.Type {
 	return reflect.PtrTo(null.column.ScanType())
 }
 
+func (null *Nullable) defaultValue() interface{} {
+	return nil
+}
+
 func (null *Nullable) Read(decoder *binary.Decoder, isNull bool) (interface{}, error) {
 	return null.column.Read(decoder, isNull)
 }
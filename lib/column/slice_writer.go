@@ -0,0 +1,225 @@
+package column
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/lib/binary"
+	sdecimal "github.com/shopspring/decimal"
+)
+
+// SliceWriter is implemented by the columns which write a whole slice of values
+// checking its type once instead of for every value as Write does.
+// ok is false when the type of the slice is not supported, nothing is written then.
+type SliceWriter interface {
+	WriteSlice(encoder *binary.Encoder, v interface{}) (n int, ok bool, err error)
+}
+
+// WriteSlice writes []int64 (unscaled values, as Write), []float64, []string and []decimal.Decimal.
+func (d *Decimal) WriteSlice(encoder *binary.Encoder, v interface{}) (int, bool, error) {
+	switch values := v.(type) {
+	case []int64:
+		for i, v := range values {
+			if err := d.writeInt64(encoder, v); err != nil {
+				return 0, true, fmt.Errorf("row %d: %w", i, err)
+			}
+		}
+		return len(values), true, nil
+	case []float64:
+		for i, v := range values {
+			if err := d.writeInt64(encoder, d.float2int64(v)); err != nil {
+				return 0, true, fmt.Errorf("row %d: %w", i, err)
+			}
+		}
+		return len(values), true, nil
+	case []string:
+		for i, v := range values {
+			dec, err := sdecimal.NewFromString(v)
+			if err != nil {
+				return 0, true, fmt.Errorf("row %d: %w", i, err)
+			}
+			if err := d.writeBigInt(encoder, dec.Shift(int32(d.scale)).BigInt()); err != nil {
+				return 0, true, fmt.Errorf("row %d: %w", i, err)
+			}
+		}
+		return len(values), true, nil
+	case []sdecimal.Decimal:
+		for i, v := range values {
+			if err := d.writeBigInt(encoder, v.Shift(int32(d.scale)).BigInt()); err != nil {
+				return 0, true, fmt.Errorf("row %d: %w", i, err)
+			}
+		}
+		return len(values), true, nil
+	}
+	return 0, false, nil
+}
+
+func (d *Decimal) writeInt64(encoder *binary.Encoder, v int64) error {
+	switch d.nobits {
+	case 32:
+		if v > math.MaxInt32 || v < math.MinInt32 {
+			return errors.New("overflow when narrowing type conversion from int64 to int32")
+		}
+		return encoder.Int32(int32(v))
+	case 64:
+		return encoder.Int64(v)
+	}
+	return encoder.Decimal128(int64ToDecimal128(v))
+}
+
+func (d *Decimal) writeBigInt(encoder *binary.Encoder, v *big.Int) error {
+	if d.nobits == 128 {
+		if v.BitLen() > 127 {
+			return fmt.Errorf("value %s overflows Decimal128", v)
+		}
+		return encoder.Decimal128(bigIntToDecimal128(v))
+	}
+	if !v.IsInt64() {
+		return fmt.Errorf("value %s overflows Decimal%d", v, d.nobits)
+	}
+	return d.writeInt64(encoder, v.Int64())
+}
+
+// WriteSlice writes []string and [][]byte (16 bytes).
+func (u *UUID) WriteSlice(encoder *binary.Encoder, v interface{}) (int, bool, error) {
+	switch values := v.(type) {
+	case []string:
+		for i, v := range values {
+			uuid, err := uuid2bytes(v)
+			if err != nil {
+				return 0, true, fmt.Errorf("row %d: %w", i, err)
+			}
+			if _, err := encoder.Write(swap(uuid)); err != nil {
+				return 0, true, err
+			}
+		}
+		return len(values), true, nil
+	case [][]byte:
+		uuid := make([]byte, UUIDLen)
+		for i, v := range values {
+			if len(v) != UUIDLen {
+				return 0, true, fmt.Errorf("row %d: invalid raw UUID len (expected %d, got %d)", i, UUIDLen, len(v))
+			}
+			copy(uuid, v)
+			if _, err := encoder.Write(swap(uuid)); err != nil {
+				return 0, true, err
+			}
+		}
+		return len(values), true, nil
+	}
+	return 0, false, nil
+}
+
+// WriteSlice writes []string (the names of the values), and []int8 or []int16 for Enum8 or Enum16.
+func (enum *Enum) WriteSlice(encoder *binary.Encoder, v interface{}) (int, bool, error) {
+	_, isEnum16 := enum.baseType.(int16)
+	switch values := v.(type) {
+	case []string:
+		for i, v := range values {
+			ident, found := enum.iv[v]
+			if !found {
+				return 0, true, fmt.Errorf("row %d: invalid Enum ident: %s", i, v)
+			}
+			var err error
+			if isEnum16 {
+				err = encoder.Int16(ident.(int16))
+			} else {
+				err = encoder.Int8(ident.(int8))
+			}
+			if err != nil {
+				return 0, true, err
+			}
+		}
+		return len(values), true, nil
+	case []int8:
+		if isEnum16 {
+			return 0, false, nil
+		}
+		for _, v := range values {
+			if err := encoder.Int8(v); err != nil {
+				return 0, true, err
+			}
+		}
+		return len(values), true, nil
+	case []int16:
+		if !isEnum16 {
+			return 0, false, nil
+		}
+		for _, v := range values {
+			if err := encoder.Int16(v); err != nil {
+				return 0, true, err
+			}
+		}
+		return len(values), true, nil
+	}
+	return 0, false, nil
+}
+
+// WriteSlice writes []time.Time and []int64 (nanoseconds, as Write).
+func (dt *DateTime64) WriteSlice(encoder *binary.Encoder, v interface{}) (int, bool, error) {
+	switch v.(type) {
+	case []time.Time, []int64:
+	default:
+		return 0, false, nil
+	}
+	precision, err := dt.getPrecision()
+	if err != nil {
+		return 0, true, err
+	}
+	divisor := int64(math.Pow10(9 - precision))
+	switch values := v.(type) {
+	case []time.Time:
+		for _, v := range values {
+			var timestamp int64
+			if !v.IsZero() {
+				timestamp = v.UnixNano()
+			}
+			if err := encoder.Int64(timestamp / divisor); err != nil {
+				return 0, true, err
+			}
+		}
+		return len(values), true, nil
+	case []int64:
+		for _, v := range values {
+			if err := encoder.Int64(v / divisor); err != nil {
+				return 0, true, err
+			}
+		}
+		return len(values), true, nil
+	}
+	return 0, false, nil
+}
+
+// WriteSlice writes []net.IP and []string.
+func (ip *IPv6) WriteSlice(encoder *binary.Encoder, v interface{}) (int, bool, error) {
+	switch values := v.(type) {
+	case []net.IP:
+		for i, v := range values {
+			if err := writeIPv6(encoder, v); err != nil {
+				return 0, true, fmt.Errorf("row %d: %w", i, err)
+			}
+		}
+		return len(values), true, nil
+	case []string:
+		for i, v := range values {
+			if err := writeIPv6(encoder, net.ParseIP(v)); err != nil {
+				return 0, true, fmt.Errorf("row %d: %w", i, err)
+			}
+		}
+		return len(values), true, nil
+	}
+	return 0, false, nil
+}
+
+func writeIPv6(encoder *binary.Encoder, ip net.IP) error {
+	ip = ip.To16()
+	if ip == nil {
+		return errors.New("invalid IPv6 address")
+	}
+	_, err := encoder.Write(ip)
+	return err
+}
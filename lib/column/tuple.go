@@ -108,3 +108,8 @@ func parseTuple(name, chType string, timezone *time.Location) (Column, error) {
 		columns: columns,
 	}, nil
 }
+
+// GetColumns returns the columns of the tuple elements.
+func (tuple *Tuple) GetColumns() []Column {
+	return tuple.columns
+}
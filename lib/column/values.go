@@ -0,0 +1,296 @@
+package column
+
+import (
+	b "encoding/binary"
+	"math"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/lib/binary"
+)
+
+// Values are the decoded values of a column in a block. Numbers, strings and dates are
+// kept in typed slices and a value is boxed into interface{} only when it is requested.
+type Values interface {
+	Len() int
+	// Value returns the value of the row as it is returned by database/sql.
+	Value(row int) interface{}
+}
+
+type Int8Values []int8
+
+func (v Int8Values) Len() int                  { return len(v) }
+func (v Int8Values) Value(row int) interface{} { return v[row] }
+
+type Int16Values []int16
+
+func (v Int16Values) Len() int                  { return len(v) }
+func (v Int16Values) Value(row int) interface{} { return v[row] }
+
+type Int32Values []int32
+
+func (v Int32Values) Len() int                  { return len(v) }
+func (v Int32Values) Value(row int) interface{} { return v[row] }
+
+type Int64Values []int64
+
+func (v Int64Values) Len() int                  { return len(v) }
+func (v Int64Values) Value(row int) interface{} { return v[row] }
+
+type UInt8Values []uint8
+
+func (v UInt8Values) Len() int                  { return len(v) }
+func (v UInt8Values) Value(row int) interface{} { return v[row] }
+
+type UInt16Values []uint16
+
+func (v UInt16Values) Len() int                  { return len(v) }
+func (v UInt16Values) Value(row int) interface{} { return v[row] }
+
+type UInt32Values []uint32
+
+func (v UInt32Values) Len() int                  { return len(v) }
+func (v UInt32Values) Value(row int) interface{} { return v[row] }
+
+type UInt64Values []uint64
+
+func (v UInt64Values) Len() int                  { return len(v) }
+func (v UInt64Values) Value(row int) interface{} { return v[row] }
+
+type Float32Values []float32
+
+func (v Float32Values) Len() int                  { return len(v) }
+func (v Float32Values) Value(row int) interface{} { return v[row] }
+
+type Float64Values []float64
+
+func (v Float64Values) Len() int                  { return len(v) }
+func (v Float64Values) Value(row int) interface{} { return v[row] }
+
+// StringValues are the values of a String or FixedString column, kept in a single
+// string with the end offset of every value.
+type StringValues struct {
+	data    string
+	offsets []int
+}
+
+func (v *StringValues) Len() int                  { return len(v.offsets) }
+func (v *StringValues) Value(row int) interface{} { return v.String(row) }
+
+// String returns the value of the row, sharing the memory of the column.
+func (v *StringValues) String(row int) string {
+	var start int
+	if row > 0 {
+		start = v.offsets[row-1]
+	}
+	return v.data[start:v.offsets[row]]
+}
+
+// Strings returns all the values, sharing the memory of the column.
+func (v *StringValues) Strings() []string {
+	values := make([]string, len(v.offsets))
+	for row := range values {
+		values[row] = v.String(row)
+	}
+	return values
+}
+
+// TimeValues are the values of a Date or DateTime column, kept as Unix timestamps.
+type TimeValues struct {
+	unix     []int64
+	location *time.Location
+}
+
+func (v *TimeValues) Len() int                  { return len(v.unix) }
+func (v *TimeValues) Value(row int) interface{} { return v.Time(row) }
+
+// Time returns the value of the row in the time zone of the column.
+func (v *TimeValues) Time(row int) time.Time {
+	return time.Unix(v.unix[row], 0).In(v.location)
+}
+
+// Times returns all the values in the time zone of the column.
+func (v *TimeValues) Times() []time.Time {
+	values := make([]time.Time, len(v.unix))
+	for row := range values {
+		values[row] = v.Time(row)
+	}
+	return values
+}
+
+// InterfaceValues are the values of the other columns (Nullable, Array, Enum, UUID, ...),
+// boxed when they are decoded.
+type InterfaceValues []interface{}
+
+func (v InterfaceValues) Len() int                  { return len(v) }
+func (v InterfaceValues) Value(row int) interface{} { return v[row] }
+
+// readChunkSize is the size of the buffer fixed size values are decoded from.
+const readChunkSize = 4096
+
+// ReadValues decodes the values of rows rows of the column.
+func ReadValues(column Column, decoder *binary.Decoder, rows int) (Values, error) {
+	switch column := column.(type) {
+	case *Int8:
+		values := make(Int8Values, rows)
+		err := readFixed(decoder, rows, 1, func(row int, buf []byte) {
+			values[row] = int8(buf[0])
+		})
+		return values, err
+	case *Int16:
+		values := make(Int16Values, rows)
+		err := readFixed(decoder, rows, 2, func(row int, buf []byte) {
+			values[row] = int16(b.LittleEndian.Uint16(buf))
+		})
+		return values, err
+	case *Int32:
+		values := make(Int32Values, rows)
+		err := readFixed(decoder, rows, 4, func(row int, buf []byte) {
+			values[row] = int32(b.LittleEndian.Uint32(buf))
+		})
+		return values, err
+	case *Int64:
+		values := make(Int64Values, rows)
+		err := readFixed(decoder, rows, 8, func(row int, buf []byte) {
+			values[row] = int64(b.LittleEndian.Uint64(buf))
+		})
+		return values, err
+	case *UInt8:
+		values := make(UInt8Values, rows)
+		if err := decoder.ReadFull(values); err != nil {
+			return nil, err
+		}
+		return values, nil
+	case *UInt16:
+		values := make(UInt16Values, rows)
+		err := readFixed(decoder, rows, 2, func(row int, buf []byte) {
+			values[row] = b.LittleEndian.Uint16(buf)
+		})
+		return values, err
+	case *UInt32:
+		values := make(UInt32Values, rows)
+		err := readFixed(decoder, rows, 4, func(row int, buf []byte) {
+			values[row] = b.LittleEndian.Uint32(buf)
+		})
+		return values, err
+	case *UInt64:
+		values := make(UInt64Values, rows)
+		err := readFixed(decoder, rows, 8, func(row int, buf []byte) {
+			values[row] = b.LittleEndian.Uint64(buf)
+		})
+		return values, err
+	case *Float32:
+		values := make(Float32Values, rows)
+		err := readFixed(decoder, rows, 4, func(row int, buf []byte) {
+			values[row] = math.Float32frombits(b.LittleEndian.Uint32(buf))
+		})
+		return values, err
+	case *Float64:
+		values := make(Float64Values, rows)
+		err := readFixed(decoder, rows, 8, func(row int, buf []byte) {
+			values[row] = math.Float64frombits(b.LittleEndian.Uint64(buf))
+		})
+		return values, err
+	case *String:
+		return readStrings(decoder, rows)
+	case *FixedString:
+		data := make([]byte, rows*column.len)
+		if err := decoder.ReadFull(data); err != nil {
+			return nil, err
+		}
+		values := &StringValues{
+			data:    string(data),
+			offsets: make([]int, rows),
+		}
+		for row := range values.offsets {
+			values.offsets[row] = (row + 1) * column.len
+		}
+		return values, nil
+	case *Date:
+		values := &TimeValues{
+			unix:     make([]int64, rows),
+			location: column.Timezone,
+		}
+		err := readFixed(decoder, rows, 2, func(row int, buf []byte) {
+			values.unix[row] = int64(int16(b.LittleEndian.Uint16(buf)))*24*3600 - column.offset
+		})
+		return values, err
+	case *DateTime:
+		values := &TimeValues{
+			unix:     make([]int64, rows),
+			location: column.Timezone,
+		}
+		err := readFixed(decoder, rows, 4, func(row int, buf []byte) {
+			values.unix[row] = int64(int32(b.LittleEndian.Uint32(buf)))
+		})
+		return values, err
+	case *Array:
+		values, err := column.ReadArray(decoder, rows)
+		return InterfaceValues(values), err
+	case *Nullable:
+		values, err := column.ReadNull(decoder, rows)
+		return InterfaceValues(values), err
+	case *Tuple:
+		values, err := column.ReadTuple(decoder, rows)
+		return InterfaceValues(values), err
+	}
+	values := make(InterfaceValues, rows)
+	for row := range values {
+		value, err := column.Read(decoder, false)
+		if err != nil {
+			return nil, err
+		}
+		values[row] = value
+	}
+	return values, nil
+}
+
+// readFixed decodes rows values of size bytes in chunks, calling set with the bytes of every value.
+func readFixed(decoder *binary.Decoder, rows, size int, set func(row int, buf []byte)) error {
+	chunk := rows * size
+	if chunk > readChunkSize {
+		chunk = readChunkSize
+	}
+	buf := make([]byte, chunk)
+	for row := 0; row < rows; {
+		n := rows - row
+		if n > len(buf)/size {
+			n = len(buf) / size
+		}
+		if err := decoder.ReadFull(buf[:n*size]); err != nil {
+			return err
+		}
+		for i := 0; i < n; i++ {
+			set(row+i, buf[i*size:(i+1)*size])
+		}
+		row += n
+	}
+	return nil
+}
+
+func readStrings(decoder *binary.Decoder, rows int) (*StringValues, error) {
+	var (
+		data    []byte
+		offsets = make([]int, rows)
+	)
+	for row := range offsets {
+		ln, err := decoder.Uvarint()
+		if err != nil {
+			return nil, err
+		}
+		start := len(data)
+		if n := start + int(ln); n > cap(data) {
+			grown := make([]byte, start, 2*cap(data)+int(ln))
+			copy(grown, data)
+			data = grown
+		}
+		data = data[:start+int(ln)]
+		if err := decoder.ReadFull(data[start:]); err != nil {
+			return nil, err
+		}
+		offsets[row] = len(data)
+	}
+	return &StringValues{
+		data:    string(data),
+		offsets: offsets,
+	}, nil
+}
@@ -15,13 +15,15 @@ import (
 type offset [][]int
 
 type Block struct {
-	Values     [][]interface{}
+	Values     []column.Values
 	Columns    []column.Column
 	NumRows    uint64
 	NumColumns uint64
 	offsets    []offset
 	buffers    []*buffer
+	pending    []uint64
 	info       blockInfo
+	readSize   int64
 }
 
 func (block *Block) Copy() *Block {
@@ -32,6 +34,11 @@ func (block *Block) Copy() *Block {
 	}
 }
 
+// Value returns the value of the column c in the row, boxed as for database/sql.
+func (block *Block) Value(c, row int) interface{} {
+	return block.Values[c].Value(row)
+}
+
 func (block *Block) ColumnNames() []string {
 	names := make([]string, 0, len(block.Columns))
 	for _, column := range block.Columns {
@@ -40,7 +47,16 @@ func (block *Block) ColumnNames() []string {
 	return names
 }
 
+// ReadSize returns the number of bytes the block was decoded from by Read.
+func (block *Block) ReadSize() int64 {
+	return block.readSize
+}
+
 func (block *Block) Read(serverInfo *ServerInfo, decoder *binary.Decoder) (err error) {
+	start := decoder.BytesRead()
+	defer func() {
+		block.readSize = decoder.BytesRead() - start
+	}()
 	if serverInfo.Revision > 0 {
 		if err = block.info.read(decoder); err != nil {
 			return err
@@ -53,15 +69,9 @@ func (block *Block) Read(serverInfo *ServerInfo, decoder *binary.Decoder) (err e
 	if block.NumRows, err = decoder.Uvarint(); err != nil {
 		return err
 	}
-	block.Values = make([][]interface{}, block.NumColumns)
-	if block.NumRows > 10 {
-		for i := 0; i < int(block.NumColumns); i++ {
-			block.Values[i] = make([]interface{}, 0, block.NumRows)
-		}
-	}
+	block.Values = make([]column.Values, block.NumColumns)
 	for i := 0; i < int(block.NumColumns); i++ {
 		var (
-			value      interface{}
 			columnName string
 			columnType string
 		)
@@ -76,26 +86,8 @@ func (block *Block) Read(serverInfo *ServerInfo, decoder *binary.Decoder) (err e
 			return err
 		}
 		block.Columns = append(block.Columns, c)
-		switch column := c.(type) {
-		case *column.Array:
-			if block.Values[i], err = column.ReadArray(decoder, int(block.NumRows)); err != nil {
-				return err
-			}
-		case *column.Nullable:
-			if block.Values[i], err = column.ReadNull(decoder, int(block.NumRows)); err != nil {
-				return err
-			}
-		case *column.Tuple:
-			if block.Values[i], err = column.ReadTuple(decoder, int(block.NumRows)); err != nil {
-				return err
-			}
-		default:
-			for row := 0; row < int(block.NumRows); row++ {
-				if value, err = column.Read(decoder, false); err != nil {
-					return err
-				}
-				block.Values[i] = append(block.Values[i], value)
-			}
+		if block.Values[i], err = column.ReadValues(c, decoder, int(block.NumRows)); err != nil {
+			return err
 		}
 	}
 	return nil
@@ -138,6 +130,11 @@ func (block *Block) AppendRow(args []driver.Value) error {
 		return fmt.Errorf("block: expected %d arguments (columns: %s), got %d", len(block.Columns), strings.Join(block.ColumnNames(), ", "), len(args))
 	}
 	block.Reserve()
+	for _, rows := range block.pending {
+		if rows != 0 {
+			return fmt.Errorf("block: cannot append a row while columns are partially appended")
+		}
+	}
 	{
 		block.NumRows++
 	}
@@ -168,21 +165,29 @@ func (block *Block) Reserve() {
 	if len(block.buffers) == 0 {
 		block.buffers = make([]*buffer, len(block.Columns))
 		block.offsets = make([]offset, len(block.Columns))
-		for i := 0; i < len(block.Columns); i++ {
-			var (
-				offsetBuffer = new(bytes.Buffer)
-				columnBuffer = new(bytes.Buffer)
-			)
-			block.buffers[i] = &buffer{
-				Offset:       binary.NewEncoder(offsetBuffer),
-				Column:       binary.NewEncoder(columnBuffer),
-				offsetBuffer: offsetBuffer,
-				columnBuffer: columnBuffer,
-			}
+		block.pending = make([]uint64, len(block.Columns))
+		for i, col := range block.Columns {
+			block.buffers[i] = newBuffer(col)
 		}
 	}
 }
 
+// Size returns the number of bytes buffered for the appended rows.
+func (block *Block) Size() int {
+	var size int
+	for _, buffer := range block.buffers {
+		for _, ln := range buffer.lens() {
+			size += ln
+		}
+	}
+	for _, offsets := range block.offsets {
+		for _, level := range offsets {
+			size += 8 * len(level)
+		}
+	}
+	return size
+}
+
 func (block *Block) Reset() {
 	block.NumRows = 0
 	block.NumColumns = 0
@@ -195,6 +200,7 @@ func (block *Block) Reset() {
 	{
 		block.offsets = nil
 		block.buffers = nil
+		block.pending = nil
 	}
 }
 
@@ -296,6 +302,46 @@ type buffer struct {
 	Column       *binary.Encoder
 	offsetBuffer *bytes.Buffer
 	columnBuffer *bytes.Buffer
+	elements     []*buffer // the buffers of the elements of a Tuple column, written one after another
+}
+
+func newBuffer(col column.Column) *buffer {
+	var (
+		offsetBuffer = new(bytes.Buffer)
+		columnBuffer = new(bytes.Buffer)
+		buf          = &buffer{
+			Offset:       binary.NewEncoder(offsetBuffer),
+			Column:       binary.NewEncoder(columnBuffer),
+			offsetBuffer: offsetBuffer,
+			columnBuffer: columnBuffer,
+		}
+	)
+	if tuple, ok := col.(*column.Tuple); ok {
+		for _, element := range tuple.GetColumns() {
+			buf.elements = append(buf.elements, newBuffer(element))
+		}
+	}
+	return buf
+}
+
+// lens returns the lengths of the buffers, including the ones of the Tuple elements.
+func (buf *buffer) lens() []int {
+	lens := []int{buf.offsetBuffer.Len(), buf.columnBuffer.Len()}
+	for _, element := range buf.elements {
+		lens = append(lens, element.lens()...)
+	}
+	return lens
+}
+
+// truncate truncates the buffers to the lengths returned by lens.
+func (buf *buffer) truncate(lens []int) []int {
+	buf.offsetBuffer.Truncate(lens[0])
+	buf.columnBuffer.Truncate(lens[1])
+	lens = lens[2:]
+	for _, element := range buf.elements {
+		lens = element.truncate(lens)
+	}
+	return lens
 }
 
 func (buf *buffer) WriteTo(w io.Writer) (int64, error) {
@@ -314,10 +360,20 @@ func (buf *buffer) WriteTo(w io.Writer) (int64, error) {
 		}
 		size += ln
 	}
+	for _, element := range buf.elements {
+		ln, err := element.WriteTo(w)
+		if err != nil {
+			return size, err
+		}
+		size += ln
+	}
 	return size, nil
 }
 
 func (buf *buffer) reset() {
 	buf.offsetBuffer.Reset()
 	buf.columnBuffer.Reset()
+	for _, element := range buf.elements {
+		element.reset()
+	}
 }
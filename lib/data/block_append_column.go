@@ -0,0 +1,303 @@
+package data
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/lib/column"
+)
+
+// AppendColumn appends all the values of the slice v to the column c.
+// The type of the slice is checked once for the whole column, common types
+// ([]int64, []string, []time.Time, ...) are encoded without boxing every value.
+// Decimal, UUID, Enum, DateTime64 and IPv6 columns have their own fast paths
+// (see column.SliceWriter). Slices of other types are written value by value
+// as with AppendRow, e.g. []*T for Nullable(T) or [][]T for Array(T).
+// A Tuple column is appended with one slice per element of the tuple, e.g.
+// []interface{}{[]uint8{1, 2}, []string{"a", "b"}} for Tuple(UInt8, String).
+//
+// The rows are added to NumRows once every column of the block has been
+// appended with the same number of values. If a value cannot be written
+// the column is left as it was before the call.
+func (block *Block) AppendColumn(c int, v interface{}) error {
+	if c < 0 || c >= len(block.Columns) {
+		return fmt.Errorf("block: column index %d out of range (columns: %d)", c, len(block.Columns))
+	}
+	block.Reserve()
+	var (
+		buf        = block.buffers[c]
+		bufferLens = buf.lens()
+		levelsLens = make([]int, len(block.offsets[c]))
+	)
+	for i, offsets := range block.offsets[c] {
+		levelsLens[i] = len(offsets)
+	}
+	n, err := block.appendColumn(block.Columns[c], buf, c, v)
+	if err != nil {
+		buf.truncate(bufferLens)
+		block.offsets[c] = block.offsets[c][:len(levelsLens)]
+		for i, ln := range levelsLens {
+			block.offsets[c][i] = block.offsets[c][i][:ln]
+		}
+		return err
+	}
+	block.pending[c] += uint64(n)
+	for _, rows := range block.pending {
+		if rows != block.pending[c] {
+			return nil
+		}
+	}
+	block.NumRows += block.pending[c]
+	for i := range block.pending {
+		block.pending[i] = 0
+	}
+	return nil
+}
+
+// CheckColumns returns an error if the columns were appended with different numbers of rows.
+func (block *Block) CheckColumns() error {
+	for i, rows := range block.pending {
+		if rows != block.pending[0] {
+			return fmt.Errorf("block: column %s has %d rows, column %s has %d rows",
+				block.Columns[0].Name(), block.NumRows+block.pending[0],
+				block.Columns[i].Name(), block.NumRows+rows,
+			)
+		}
+	}
+	return nil
+}
+
+// appendColumn writes the slice to the buffer of the column, c is the index of the column
+// in the block or -1 for an element of a Tuple column.
+func (block *Block) appendColumn(col column.Column, buf *buffer, c int, v interface{}) (int, error) {
+	encoder := buf.Column
+	switch values := v.(type) {
+	case []int8:
+		if _, ok := col.(*column.Int8); ok {
+			for _, v := range values {
+				if err := encoder.Int8(v); err != nil {
+					return 0, err
+				}
+			}
+			return len(values), nil
+		}
+	case []int16:
+		if _, ok := col.(*column.Int16); ok {
+			for _, v := range values {
+				if err := encoder.Int16(v); err != nil {
+					return 0, err
+				}
+			}
+			return len(values), nil
+		}
+	case []int32:
+		if _, ok := col.(*column.Int32); ok {
+			for _, v := range values {
+				if err := encoder.Int32(v); err != nil {
+					return 0, err
+				}
+			}
+			return len(values), nil
+		}
+	case []int64:
+		if _, ok := col.(*column.Int64); ok {
+			for _, v := range values {
+				if err := encoder.Int64(v); err != nil {
+					return 0, err
+				}
+			}
+			return len(values), nil
+		}
+	case []int:
+		if _, ok := col.(*column.Int64); ok {
+			for _, v := range values {
+				if err := encoder.Int64(int64(v)); err != nil {
+					return 0, err
+				}
+			}
+			return len(values), nil
+		}
+	case []uint8:
+		if _, ok := col.(*column.UInt8); ok {
+			if _, err := encoder.Write(values); err != nil {
+				return 0, err
+			}
+			return len(values), nil
+		}
+	case []bool:
+		if _, ok := col.(*column.UInt8); ok {
+			for _, v := range values {
+				if err := encoder.Bool(v); err != nil {
+					return 0, err
+				}
+			}
+			return len(values), nil
+		}
+	case []uint16:
+		if _, ok := col.(*column.UInt16); ok {
+			for _, v := range values {
+				if err := encoder.UInt16(v); err != nil {
+					return 0, err
+				}
+			}
+			return len(values), nil
+		}
+	case []uint32:
+		if _, ok := col.(*column.UInt32); ok {
+			for _, v := range values {
+				if err := encoder.UInt32(v); err != nil {
+					return 0, err
+				}
+			}
+			return len(values), nil
+		}
+	case []uint64:
+		if _, ok := col.(*column.UInt64); ok {
+			for _, v := range values {
+				if err := encoder.UInt64(v); err != nil {
+					return 0, err
+				}
+			}
+			return len(values), nil
+		}
+	case []float32:
+		if _, ok := col.(*column.Float32); ok {
+			for _, v := range values {
+				if err := encoder.Float32(v); err != nil {
+					return 0, err
+				}
+			}
+			return len(values), nil
+		}
+	case []float64:
+		if _, ok := col.(*column.Float64); ok {
+			for _, v := range values {
+				if err := encoder.Float64(v); err != nil {
+					return 0, err
+				}
+			}
+			return len(values), nil
+		}
+	case []string:
+		if _, ok := col.(*column.String); ok {
+			for _, v := range values {
+				if err := encoder.String(v); err != nil {
+					return 0, err
+				}
+			}
+			return len(values), nil
+		}
+	case [][]byte:
+		if _, ok := col.(*column.String); ok {
+			for _, v := range values {
+				if err := encoder.RawString(v); err != nil {
+					return 0, err
+				}
+			}
+			return len(values), nil
+		}
+	case []time.Time:
+		switch col.(type) {
+		case *column.Date:
+			for _, v := range values {
+				_, offset := v.Zone()
+				if err := encoder.Int16(int16((v.Unix() + int64(offset)) / 24 / 3600)); err != nil {
+					return 0, err
+				}
+			}
+			return len(values), nil
+		case *column.DateTime:
+			for _, v := range values {
+				var timestamp int64
+				if !v.IsZero() {
+					timestamp = v.Unix()
+				}
+				if err := encoder.Int32(int32(timestamp)); err != nil {
+					return 0, err
+				}
+			}
+			return len(values), nil
+		}
+	}
+	if tuple, ok := col.(*column.Tuple); ok {
+		return block.appendTuple(tuple, buf, v)
+	}
+	if w, ok := col.(column.SliceWriter); ok {
+		n, ok, err := w.WriteSlice(encoder, v)
+		if err != nil {
+			return 0, fmt.Errorf("block: column %s, %v", col.Name(), err)
+		}
+		if ok {
+			return n, nil
+		}
+	}
+	return block.appendColumnValues(col, buf, c, reflect.ValueOf(v))
+}
+
+// appendTuple writes the slices of the elements of the tuple to their own buffers.
+func (block *Block) appendTuple(col *column.Tuple, buf *buffer, v interface{}) (int, error) {
+	var (
+		elements  = col.GetColumns()
+		values, _ = v.([]interface{})
+	)
+	if len(values) != len(elements) {
+		return 0, fmt.Errorf("block: column %s expects one slice per element of %s, got %T", col.Name(), col.CHType(), v)
+	}
+	rows := -1
+	for i, element := range elements {
+		n, err := block.appendColumn(element, buf.elements[i], -1, values[i])
+		if err != nil {
+			return 0, fmt.Errorf("block: column %s, element %d: %w", col.Name(), i+1, err)
+		}
+		if rows != -1 && n != rows {
+			return 0, fmt.Errorf("block: column %s: element %d has %d rows, element 1 has %d rows", col.Name(), i+1, n, rows)
+		}
+		rows = n
+	}
+	return rows, nil
+}
+
+// appendColumnValues writes the slice value by value.
+func (block *Block) appendColumnValues(col column.Column, buf *buffer, c int, values reflect.Value) (int, error) {
+	if values.Kind() != reflect.Slice && values.Kind() != reflect.Array {
+		return 0, fmt.Errorf("block: column %s expects a slice of values, got %T", col.Name(), interfaceOf(values))
+	}
+	var write func(v reflect.Value) error
+	switch col := col.(type) {
+	case *column.Array:
+		if c < 0 {
+			return 0, fmt.Errorf("block: column %s: Array elements of Tuple columns are not supported", col.Name())
+		}
+		write = func(v reflect.Value) error {
+			if v.Kind() == reflect.Interface {
+				v = v.Elem()
+			}
+			if v.Kind() != reflect.Slice {
+				return fmt.Errorf("unsupported Array(T) type [%T]", interfaceOf(v))
+			}
+			return block.writeArray(col, newValue(v), c, 1)
+		}
+	case *column.Nullable:
+		write = func(v reflect.Value) error {
+			return col.WriteNull(buf.Offset, buf.Column, v.Interface())
+		}
+	default:
+		write = func(v reflect.Value) error {
+			return col.Write(buf.Column, v.Interface())
+		}
+	}
+	for i := 0; i < values.Len(); i++ {
+		if err := write(values.Index(i)); err != nil {
+			return 0, fmt.Errorf("block: column %s, row %d: %v", col.Name(), i, err)
+		}
+	}
+	return values.Len(), nil
+}
+
+func interfaceOf(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
@@ -0,0 +1,179 @@
+package data
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/lib/binary"
+	"github.com/ClickHouse/clickhouse-go/lib/column"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBlock(t *testing.T, types ...string) *Block {
+	block := &Block{NumColumns: uint64(len(types))}
+	for i, chType := range types {
+		col, err := column.Factory(string(rune('a'+i)), chType, time.UTC)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		block.Columns = append(block.Columns, col)
+	}
+	return block
+}
+
+func encodeBlock(t *testing.T, block *Block) []byte {
+	var buf bytes.Buffer
+	assert.NoError(t, block.Write(&ServerInfo{}, binary.NewEncoder(&buf)))
+	return buf.Bytes()
+}
+
+func Test_AppendColumn(t *testing.T) {
+	types := []string{
+		"Int8", "Int64", "UInt8", "UInt32", "Float64", "String", "Date", "DateTime",
+		"Nullable(String)", "Array(UInt8)", "Enum8('a' = 1, 'b' = 2)", "UUID", "IPv6", "Decimal(9,2)",
+		"DateTime64(3)", "Decimal(18,4)", "Decimal(38,2)", "Enum16('c' = 1000, 'd' = 2000)", "IPv6",
+	}
+	var (
+		now     = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+		str     = "value"
+		columns = []interface{}{
+			[]int8{1, -1},
+			[]int{42, -42},
+			[]uint8{1, 2},
+			[]uint32{3, 4},
+			[]float64{1.5, 2.5},
+			[]string{"a", "b"},
+			[]time.Time{now, now.AddDate(0, 0, 1)},
+			[]time.Time{now, {}},
+			[]*string{&str, nil},
+			[][]uint8{{1, 2, 3}, {}},
+			[]string{"a", "b"},
+			[]string{"123e4567-e89b-12d3-a456-426655440000", "00000000-0000-0000-0000-000000000000"},
+			[]string{"::1", "2001:db8::1"},
+			[]float64{1.25, 2.5},
+			[]time.Time{now.Add(123 * time.Millisecond), {}},
+			[]int64{12345, -1},
+			[]string{"1.25", "-2.5"},
+			[]int16{1000, 2000},
+			[]net.IP{net.ParseIP("::1"), net.ParseIP("10.0.0.1")},
+		}
+		rows = [][]driver.Value{
+			{int8(1), int64(42), uint8(1), uint32(3), 1.5, "a", now, now, &str, []uint8{1, 2, 3}, "a", "123e4567-e89b-12d3-a456-426655440000", "::1", 1.25,
+				now.Add(123 * time.Millisecond), int64(12345), "1.25", int16(1000), net.ParseIP("::1")},
+			{int8(-1), int64(-42), uint8(2), uint32(4), 2.5, "b", now.AddDate(0, 0, 1), time.Time{}, nil, []uint8{}, "b", "00000000-0000-0000-0000-000000000000", "2001:db8::1", 2.5,
+				time.Time{}, int64(-1), "-2.5", int16(2000), net.ParseIP("10.0.0.1")},
+		}
+		byColumn = newTestBlock(t, types...)
+		byRow    = newTestBlock(t, types...)
+	)
+	for c, values := range columns {
+		if assert.NoError(t, byColumn.AppendColumn(c, values), types[c]) && c < len(columns)-1 {
+			assert.Equal(t, uint64(0), byColumn.NumRows, "rows are complete only when all the columns are appended")
+			assert.Error(t, byColumn.CheckColumns())
+		}
+	}
+	assert.Equal(t, uint64(2), byColumn.NumRows)
+	assert.NoError(t, byColumn.CheckColumns())
+	for _, row := range rows {
+		assert.NoError(t, byRow.AppendRow(row))
+	}
+	assert.Equal(t, encodeBlock(t, byRow), encodeBlock(t, byColumn))
+}
+
+func Test_AppendColumnTuple(t *testing.T) {
+	var (
+		str   = "value"
+		block = newTestBlock(t, "UInt8", "Tuple(UInt8, Nullable(String), Tuple(String, DateTime64(3)))")
+		now   = time.Date(2020, 1, 2, 3, 4, 5, 123e6, time.UTC)
+	)
+	assert.NoError(t, block.AppendColumn(0, []uint8{1, 2}))
+	assert.NoError(t, block.AppendColumn(1, []interface{}{
+		[]uint8{3, 4},
+		[]*string{&str, nil},
+		[]interface{}{[]string{"a", "b"}, []time.Time{now, now}},
+	}))
+	assert.Equal(t, uint64(2), block.NumRows)
+	var (
+		received Block
+		encoded  = encodeBlock(t, block)
+	)
+	if assert.NoError(t, received.Read(&ServerInfo{Timezone: time.UTC}, binary.NewDecoder(bytes.NewReader(encoded)))) {
+		assert.Equal(t, uint64(2), received.NumRows)
+		for i, expected := range [][]interface{}{{uint8(3), str}, {uint8(4), nil}} {
+			if tuple, ok := received.Value(1, i).([]interface{}); assert.True(t, ok) && assert.Len(t, tuple, 3) {
+				assert.Equal(t, expected[0], tuple[0])
+				assert.Equal(t, expected[1], tuple[1])
+				if nested, ok := tuple[2].([]interface{}); assert.True(t, ok) && assert.Len(t, nested, 2) {
+					assert.Equal(t, []string{"a", "b"}[i], nested[0])
+					assert.True(t, now.Equal(nested[1].(time.Time)))
+				}
+			}
+		}
+	}
+}
+
+func Test_AppendColumnErrors(t *testing.T) {
+	block := newTestBlock(t, "UInt8", "Array(String)", "Tuple(UInt8, String)", "Tuple(Array(UInt8))", "DateTime64(3)", "Decimal(9,2)")
+	assert.Error(t, block.AppendColumn(6, []uint8{1}))
+	assert.Error(t, block.AppendColumn(0, uint8(1)))
+	assert.Error(t, block.AppendColumn(0, []string{"a"}))
+	assert.Error(t, block.AppendColumn(2, [][]interface{}{{uint8(1), "a"}}))
+	assert.Error(t, block.AppendColumn(3, []interface{}{[][]uint8{{1}}}))
+	assert.Error(t, block.AppendColumn(5, []string{"1e10"}), "overflows Decimal32")
+	if err := block.AppendColumn(2, []interface{}{[]uint8{1, 2}, []string{"a"}}); assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "element 2 has 1 rows")
+	}
+	// the failed append must not leave partial values in the elements of the tuple
+	if assert.NoError(t, block.AppendColumn(2, []interface{}{[]uint8{1}, []string{"a"}})) {
+		assert.Equal(t, []int{0, 0, 0, 1, 0, 2}, block.buffers[2].lens())
+	}
+	if err := block.AppendColumn(1, []interface{}{[]string{"a"}, 42}); assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "column b, row 1")
+	}
+	// the failed append must not leave partial values in the column
+	if assert.NoError(t, block.AppendColumn(1, [][]string{{"b", "c"}})) {
+		assert.Equal(t, offset{{2}}, block.offsets[1])
+	}
+	assert.Error(t, block.AppendRow([]driver.Value{uint8(1), []string{}, nil}))
+}
+
+func Test_BlockSize(t *testing.T) {
+	block := newTestBlock(t, "String", "Array(UInt8)")
+	assert.Equal(t, 0, block.Size())
+	assert.NoError(t, block.AppendRow([]driver.Value{"abc", []uint8{1, 2}}))
+	assert.Equal(t, 4+2+8, block.Size(), "string length and data, array values and offset")
+	block.Reset()
+	assert.Equal(t, 0, block.Size())
+}
+
+func Test_BlockRead(t *testing.T) {
+	var (
+		now   = time.Unix(time.Now().Unix(), 0)
+		str   = "value"
+		block = newTestBlock(t, "Int64", "String", "DateTime", "Nullable(String)", "Array(UInt8)")
+		rows  = [][]driver.Value{
+			{int64(1), "a", now, &str, []uint8{1, 2}},
+			{int64(-1), "b", now, nil, []uint8{}},
+		}
+	)
+	for _, row := range rows {
+		assert.NoError(t, block.AppendRow(row))
+	}
+	var (
+		received Block
+		encoded  = encodeBlock(t, block)
+	)
+	if assert.NoError(t, received.Read(&ServerInfo{Timezone: time.UTC}, binary.NewDecoder(bytes.NewReader(encoded)))) {
+		assert.Equal(t, int64(len(encoded)), received.ReadSize())
+		assert.Equal(t, uint64(2), received.NumRows)
+		assert.Equal(t, column.Int64Values{1, -1}, received.Values[0])
+		assert.Equal(t, "b", received.Value(1, 1))
+		assert.True(t, now.Equal(received.Value(2, 0).(time.Time)))
+		assert.Equal(t, str, received.Value(3, 0))
+		assert.Nil(t, received.Value(3, 1))
+		assert.Equal(t, []uint8{1, 2}, received.Value(4, 0))
+	}
+}
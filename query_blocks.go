@@ -0,0 +1,346 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/lib/column"
+	"github.com/ClickHouse/clickhouse-go/lib/data"
+)
+
+// Blocks iterates over the data blocks of a query result as they are received from the server.
+//
+//	blocks, err := ch.QueryBlocks(ctx, "SELECT id, name FROM example")
+//	if err != nil {
+//		return err
+//	}
+//	defer blocks.Close()
+//	for blocks.Next() {
+//		block := blocks.Block()
+//		ids, err := block.UInt64s(0)
+//		...
+//	}
+//	return blocks.Err()
+type Blocks struct {
+	rows  *rows
+	block *ResultBlock
+	err   error
+}
+
+// QueryBlocks runs the query and returns its result block by block, for processing the
+// values column by column instead of copying them row by row as database/sql does.
+// The arguments are bound into the query as with Query.
+//
+// The connection cannot be used for other queries until the blocks are closed.
+// With database/sql the method is available through the driver connection:
+//
+//	conn.Raw(func(driverConn interface{}) error {
+//		blocks, err := driverConn.(clickhouse.Clickhouse).QueryBlocks(ctx, "SELECT id, name FROM example WHERE id > ?", 42)
+//		...
+//	})
+func (ch *clickhouse) QueryBlocks(ctx context.Context, query string, args ...interface{}) (*Blocks, error) {
+	switch {
+	case ch.conn.closed:
+		return nil, driver.ErrBadConn
+	case ch.batch != nil:
+		return nil, ErrBatchInProgress
+	case ch.block != nil:
+		return nil, ErrLimitDataRequestInTx
+	}
+	ch.logf("[query blocks] %s", redactQuery(query))
+	stmt := &stmt{
+		ch:       ch,
+		query:    query,
+		numInput: numInput(query),
+	}
+	if stmt.numInput >= 0 && stmt.numInput != len(args) {
+		return nil, fmt.Errorf("query blocks: expected %d arguments, got %d", stmt.numInput, len(args))
+	}
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+		if err := ch.CheckNamedValue(&named[i]); err != nil {
+			return nil, err
+		}
+	}
+	result, err := stmt.queryContext(ctx, named)
+	if err != nil {
+		return nil, err
+	}
+	return &Blocks{
+		rows: result.(*rows),
+	}, nil
+}
+
+// Next receives the next block, it returns false at the end of the result or on error.
+func (blocks *Blocks) Next() bool {
+	if blocks.err != nil || blocks.rows == nil {
+		return false
+	}
+	block, ok := blocks.rows.nextBlock()
+	if !ok {
+		blocks.err = blocks.rows.error()
+		blocks.block = nil
+		return false
+	}
+	blocks.block = &ResultBlock{block: block}
+	return true
+}
+
+// Block returns the current block.
+func (blocks *Blocks) Block() *ResultBlock {
+	return blocks.block
+}
+
+// Columns returns the names of the result columns.
+func (blocks *Blocks) Columns() []string {
+	if blocks.rows == nil {
+		return nil
+	}
+	return blocks.rows.columns
+}
+
+// DataColumns returns the columns of the result, which describe their ClickHouse types.
+func (blocks *Blocks) DataColumns() []column.Column {
+	if blocks.rows == nil {
+		return nil
+	}
+	return blocks.rows.blockColumns
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (blocks *Blocks) Err() error {
+	return blocks.err
+}
+
+// Close discards the rest of the result, releasing the connection.
+func (blocks *Blocks) Close() error {
+	if blocks.rows == nil {
+		return nil
+	}
+	err := blocks.rows.Close()
+	blocks.rows, blocks.block = nil, nil
+	return err
+}
+
+// ResultBlock is a block of a query result with typed accessors to its columns.
+// The slices returned by the accessors must not be modified and are valid until the
+// next call to Blocks.Next. An accessor returns an error if the type of the column does
+// not match, e.g. Int64s for an Int64 column and Strings for a String, FixedString,
+// Enum, UUID or Decimal column. Nullable, Array and other columns are read with Values.
+type ResultBlock struct {
+	block *data.Block
+}
+
+// Rows returns the number of rows in the block.
+func (block *ResultBlock) Rows() int {
+	return int(block.block.NumRows)
+}
+
+// Columns returns the names of the columns.
+func (block *ResultBlock) Columns() []string {
+	return block.block.ColumnNames()
+}
+
+// Data returns the decoded block with the columns and their values, which must not be modified.
+func (block *ResultBlock) Data() *data.Block {
+	return block.block
+}
+
+// ColumnType returns the ClickHouse type of the column with index i.
+func (block *ResultBlock) ColumnType(i int) string {
+	return block.block.Columns[i].CHType()
+}
+
+// Values returns the values of the column with index i, as they are returned by database/sql.
+func (block *ResultBlock) Values(i int) ([]interface{}, error) {
+	values, err := block.values(i)
+	if err != nil {
+		return nil, err
+	}
+	if values, ok := values.(column.InterfaceValues); ok {
+		return values, nil
+	}
+	boxed := make([]interface{}, values.Len())
+	for row := range boxed {
+		boxed[row] = values.Value(row)
+	}
+	return boxed, nil
+}
+
+// Int8s returns the values of an Int8 column.
+func (block *ResultBlock) Int8s(i int) ([]int8, error) {
+	values, err := block.values(i)
+	if err != nil {
+		return nil, err
+	}
+	if values, ok := values.(column.Int8Values); ok {
+		return values, nil
+	}
+	return nil, block.typeError(i, "[]int8")
+}
+
+// Int16s returns the values of an Int16 column.
+func (block *ResultBlock) Int16s(i int) ([]int16, error) {
+	values, err := block.values(i)
+	if err != nil {
+		return nil, err
+	}
+	if values, ok := values.(column.Int16Values); ok {
+		return values, nil
+	}
+	return nil, block.typeError(i, "[]int16")
+}
+
+// Int32s returns the values of an Int32 column.
+func (block *ResultBlock) Int32s(i int) ([]int32, error) {
+	values, err := block.values(i)
+	if err != nil {
+		return nil, err
+	}
+	if values, ok := values.(column.Int32Values); ok {
+		return values, nil
+	}
+	return nil, block.typeError(i, "[]int32")
+}
+
+// Int64s returns the values of an Int64 column.
+func (block *ResultBlock) Int64s(i int) ([]int64, error) {
+	values, err := block.values(i)
+	if err != nil {
+		return nil, err
+	}
+	if values, ok := values.(column.Int64Values); ok {
+		return values, nil
+	}
+	return nil, block.typeError(i, "[]int64")
+}
+
+// UInt8s returns the values of a UInt8 column.
+func (block *ResultBlock) UInt8s(i int) ([]uint8, error) {
+	values, err := block.values(i)
+	if err != nil {
+		return nil, err
+	}
+	if values, ok := values.(column.UInt8Values); ok {
+		return values, nil
+	}
+	return nil, block.typeError(i, "[]uint8")
+}
+
+// UInt16s returns the values of a UInt16 column.
+func (block *ResultBlock) UInt16s(i int) ([]uint16, error) {
+	values, err := block.values(i)
+	if err != nil {
+		return nil, err
+	}
+	if values, ok := values.(column.UInt16Values); ok {
+		return values, nil
+	}
+	return nil, block.typeError(i, "[]uint16")
+}
+
+// UInt32s returns the values of a UInt32 column.
+func (block *ResultBlock) UInt32s(i int) ([]uint32, error) {
+	values, err := block.values(i)
+	if err != nil {
+		return nil, err
+	}
+	if values, ok := values.(column.UInt32Values); ok {
+		return values, nil
+	}
+	return nil, block.typeError(i, "[]uint32")
+}
+
+// UInt64s returns the values of a UInt64 column.
+func (block *ResultBlock) UInt64s(i int) ([]uint64, error) {
+	values, err := block.values(i)
+	if err != nil {
+		return nil, err
+	}
+	if values, ok := values.(column.UInt64Values); ok {
+		return values, nil
+	}
+	return nil, block.typeError(i, "[]uint64")
+}
+
+// Float32s returns the values of a Float32 column.
+func (block *ResultBlock) Float32s(i int) ([]float32, error) {
+	values, err := block.values(i)
+	if err != nil {
+		return nil, err
+	}
+	if values, ok := values.(column.Float32Values); ok {
+		return values, nil
+	}
+	return nil, block.typeError(i, "[]float32")
+}
+
+// Float64s returns the values of a Float64 column.
+func (block *ResultBlock) Float64s(i int) ([]float64, error) {
+	values, err := block.values(i)
+	if err != nil {
+		return nil, err
+	}
+	if values, ok := values.(column.Float64Values); ok {
+		return values, nil
+	}
+	return nil, block.typeError(i, "[]float64")
+}
+
+// Strings returns the values of a String, FixedString, Enum, UUID or Decimal column.
+func (block *ResultBlock) Strings(i int) ([]string, error) {
+	values, err := block.values(i)
+	if err != nil {
+		return nil, err
+	}
+	switch values := values.(type) {
+	case *column.StringValues:
+		return values.Strings(), nil
+	case column.InterfaceValues:
+		switch block.block.Columns[i].(type) {
+		case *column.Enum, *column.UUID, *column.Decimal:
+			typed := make([]string, len(values))
+			for row, v := range values {
+				typed[row] = v.(string)
+			}
+			return typed, nil
+		}
+	}
+	return nil, block.typeError(i, "[]string")
+}
+
+// Times returns the values of a Date, DateTime or DateTime64 column.
+func (block *ResultBlock) Times(i int) ([]time.Time, error) {
+	values, err := block.values(i)
+	if err != nil {
+		return nil, err
+	}
+	switch values := values.(type) {
+	case *column.TimeValues:
+		return values.Times(), nil
+	case column.InterfaceValues:
+		if _, ok := block.block.Columns[i].(*column.DateTime64); ok {
+			typed := make([]time.Time, len(values))
+			for row, v := range values {
+				typed[row] = v.(time.Time)
+			}
+			return typed, nil
+		}
+	}
+	return nil, block.typeError(i, "[]time.Time")
+}
+
+func (block *ResultBlock) values(i int) (column.Values, error) {
+	if i < 0 || i >= len(block.block.Columns) {
+		return nil, fmt.Errorf("clickhouse: column index %d out of range (columns: %d)", i, len(block.block.Columns))
+	}
+	return block.block.Values[i], nil
+}
+
+func (block *ResultBlock) typeError(i int, typ string) error {
+	col := block.block.Columns[i]
+	return fmt.Errorf("clickhouse: column %s of type %s cannot be read as %s", col.Name(), col.CHType(), typ)
+}
@@ -0,0 +1,86 @@
+package clickhouse
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/lib/binary"
+	"github.com/ClickHouse/clickhouse-go/lib/column"
+	"github.com/ClickHouse/clickhouse-go/lib/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ResultBlock(t *testing.T) {
+	var (
+		types = []string{"Int8", "Int64", "UInt8", "UInt32", "Float64", "String", "Enum8('a' = 1, 'b' = 2)", "DateTime", "Nullable(Int64)"}
+		now   = time.Unix(time.Now().Unix(), 0)
+		one   = int64(1)
+		block = &data.Block{NumColumns: uint64(len(types))}
+	)
+	for i, chType := range types {
+		col, err := column.Factory(string(rune('a'+i)), chType, time.Local)
+		if !assert.NoError(t, err) {
+			return
+		}
+		block.Columns = append(block.Columns, col)
+	}
+	for _, row := range [][]driver.Value{
+		{int8(-1), int64(42), uint8(1), uint32(3), 1.5, "x", "a", now, &one},
+		{int8(1), int64(-42), uint8(2), uint32(4), 2.5, "y", "b", now.Add(time.Second), nil},
+	} {
+		assert.NoError(t, block.AppendRow(row))
+	}
+	var buf bytes.Buffer
+	if !assert.NoError(t, block.Write(&data.ServerInfo{}, binary.NewEncoder(&buf))) {
+		return
+	}
+	received := &data.Block{}
+	if !assert.NoError(t, received.Read(&data.ServerInfo{Timezone: time.Local}, binary.NewDecoder(&buf))) {
+		return
+	}
+	result := &ResultBlock{block: received}
+	assert.Equal(t, 2, result.Rows())
+	assert.Equal(t, []string{"a", "b", "c", "d", "e", "f", "g", "h", "i"}, result.Columns())
+	assert.True(t, result.Data() == received)
+	assert.Nil(t, (&Blocks{}).DataColumns())
+	assert.Equal(t, "Nullable(Int64)", result.ColumnType(8))
+	if v, err := result.Int8s(0); assert.NoError(t, err) {
+		assert.Equal(t, []int8{-1, 1}, v)
+	}
+	if v, err := result.Int64s(1); assert.NoError(t, err) {
+		assert.Equal(t, []int64{42, -42}, v)
+	}
+	if v, err := result.UInt8s(2); assert.NoError(t, err) {
+		assert.Equal(t, []uint8{1, 2}, v)
+	}
+	if v, err := result.UInt32s(3); assert.NoError(t, err) {
+		assert.Equal(t, []uint32{3, 4}, v)
+	}
+	if v, err := result.Float64s(4); assert.NoError(t, err) {
+		assert.Equal(t, []float64{1.5, 2.5}, v)
+	}
+	if v, err := result.Strings(5); assert.NoError(t, err) {
+		assert.Equal(t, []string{"x", "y"}, v)
+	}
+	if v, err := result.Strings(6); assert.NoError(t, err) {
+		assert.Equal(t, []string{"a", "b"}, v)
+	}
+	if v, err := result.Times(7); assert.NoError(t, err) && assert.Len(t, v, 2) {
+		assert.True(t, now.Equal(v[0]))
+		assert.True(t, now.Add(time.Second).Equal(v[1]))
+	}
+	if v, err := result.Values(8); assert.NoError(t, err) {
+		assert.Equal(t, []interface{}{one, nil}, v)
+	}
+	if _, err := result.Int64s(8); assert.Error(t, err) {
+		assert.Equal(t, "clickhouse: column i of type Nullable(Int64) cannot be read as []int64", err.Error())
+	}
+	_, err := result.Int32s(1)
+	assert.Error(t, err)
+	_, err = result.Strings(9)
+	assert.Error(t, err)
+	_, err = result.Values(-1)
+	assert.Error(t, err)
+}
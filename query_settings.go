@@ -1,9 +1,13 @@
 package clickhouse
 
 import (
+	"context"
 	"fmt"
 	"net/url"
+	"reflect"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ClickHouse/clickhouse-go/lib/binary"
 )
@@ -284,3 +288,86 @@ func (qs *querySettings) Serialize(enc *binary.Encoder) error {
 
 	return nil
 }
+
+// contextSettings are query settings set by the driver for a query (deduplication tokens,
+// async inserts, timeouts), on top of the settings given in the DSN.
+// The values can be bools, integers, floats, strings or time.Duration; durations
+// are sent in milliseconds for the settings with the _ms suffix and in seconds otherwise.
+type contextSettings map[string]interface{}
+
+var querySettingsKey key = "query_settings"
+
+// withSettings puts query settings into context, they are merged with the settings
+// already in the context and override the ones with the same name.
+func withSettings(ctx context.Context, settings contextSettings) context.Context {
+	merged := make(contextSettings, len(settings))
+	if current, ok := ctx.Value(querySettingsKey).(contextSettings); ok {
+		for name, value := range current {
+			merged[name] = value
+		}
+	}
+	for name, value := range settings {
+		merged[name] = value
+	}
+	return context.WithValue(ctx, querySettingsKey, merged)
+}
+
+// withContext returns the settings extended with the ones from the context.
+func (qs *querySettings) withContext(ctx context.Context) (*querySettings, error) {
+	settings, ok := ctx.Value(querySettingsKey).(contextSettings)
+	if !ok || len(settings) == 0 {
+		return qs, nil
+	}
+	merged := &querySettings{
+		settings: make(map[string]querySettingValueEncoder, len(qs.settings)+len(settings)),
+	}
+	for name, fn := range qs.settings {
+		merged.settings[name] = fn
+	}
+	str := make([]string, 0, len(settings))
+	for name, value := range settings {
+		fn, err := encodeSetting(name, value)
+		if err != nil {
+			return nil, err
+		}
+		merged.settings[name] = fn
+		str = append(str, fmt.Sprintf("%s=%v", name, value))
+	}
+	merged.settingsStr = strings.Join(append([]string{qs.settingsStr}, str...), "&")
+	if qs.settingsStr == "" {
+		merged.settingsStr = strings.Join(str, "&")
+	}
+	return merged, nil
+}
+
+func encodeSetting(name string, value interface{}) (querySettingValueEncoder, error) {
+	var uvarint uint64
+	switch v := value.(type) {
+	case string:
+		return func(enc *binary.Encoder) error { return enc.String(v) }, nil
+	case float32, float64:
+		str := fmt.Sprint(v)
+		return func(enc *binary.Encoder) error { return enc.String(str) }, nil
+	case bool:
+		if v {
+			uvarint = 1
+		}
+	case time.Duration:
+		if strings.HasSuffix(name, "_ms") {
+			uvarint = uint64(v / time.Millisecond)
+		} else {
+			uvarint = uint64(v / time.Second)
+		}
+	case int, int8, int16, int32, int64:
+		n := reflect.ValueOf(v).Int()
+		if n < 0 {
+			return nil, fmt.Errorf("query setting %s has negative value %d", name, n)
+		}
+		uvarint = uint64(n)
+	case uint, uint8, uint16, uint32, uint64:
+		uvarint = reflect.ValueOf(v).Uint()
+	default:
+		return nil, fmt.Errorf("query setting %s has unsupported type %T", name, value)
+	}
+	return func(enc *binary.Encoder) error { return enc.Uvarint(uvarint) }, nil
+}
@@ -0,0 +1,68 @@
+package clickhouse
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/lib/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_QuerySettingsWithContext(t *testing.T) {
+	dsn, err := makeQuerySettings(url.Values{"max_threads": {"4"}, "extremes": {"1"}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	if qs, err := dsn.withContext(context.Background()); assert.NoError(t, err) {
+		assert.True(t, qs == dsn)
+	}
+	ctx := withSettings(context.Background(), contextSettings{"max_threads": 8, "insert_deduplication_token": "a"})
+	ctx = withSettings(ctx, contextSettings{"insert_deduplication_token": "b"})
+	qs, err := dsn.withContext(ctx)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, qs.settings, 3)
+	assert.Len(t, dsn.settings, 2, "the DSN settings must not be modified")
+	for name, expected := range map[string][]byte{
+		"max_threads":                {8},
+		"extremes":                   {1},
+		"insert_deduplication_token": {1, 'b'},
+	} {
+		var buf bytes.Buffer
+		if assert.NoError(t, qs.settings[name](binary.NewEncoder(&buf))) {
+			assert.Equal(t, expected, buf.Bytes(), name)
+		}
+	}
+	_, err = dsn.withContext(withSettings(context.Background(), contextSettings{"max_threads": -1}))
+	assert.Error(t, err)
+	_, err = dsn.withContext(withSettings(context.Background(), contextSettings{"max_threads": []int{1}}))
+	assert.Error(t, err)
+}
+
+func Test_EncodeSetting(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		value    interface{}
+		expected []byte
+	}{
+		{"async_insert", true, []byte{1}},
+		{"async_insert", false, []byte{0}},
+		{"max_execution_time", 90 * time.Second, []byte{90}},
+		{"async_insert_busy_timeout_ms", 200 * time.Millisecond, []byte{0xc8, 0x01}},
+		{"max_block_size", uint64(300), []byte{0xac, 0x02}},
+		{"max_threads", int8(2), []byte{2}},
+		{"totals_auto_threshold", 0.5, []byte{3, '0', '.', '5'}},
+	} {
+		fn, err := encodeSetting(test.name, test.value)
+		if assert.NoError(t, err) {
+			var buf bytes.Buffer
+			if assert.NoError(t, fn(binary.NewEncoder(&buf))) {
+				assert.Equal(t, test.expected, buf.Bytes(), test.name)
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultDeadlineMargin is how much earlier than the context deadline the server stops the query.
+const DefaultDeadlineMargin = time.Second
+
+// codes of the exceptions thrown by the server when it stops a query
+const (
+	codeTimeoutExceeded   = 159 // max_execution_time is exceeded
+	codeQueryWasCancelled = 394 // the client canceled the query
+)
+
+// CanceledError is returned when a query failed because its context was canceled or its deadline
+// passed before the server returned a result: the driver canceled the query and closed the connection.
+type CanceledError struct {
+	// Err is the error of the context, context.Canceled or context.DeadlineExceeded.
+	Err error
+	// Cause is the error with which the query failed.
+	Cause error
+}
+
+func (e *CanceledError) Error() string {
+	return fmt.Sprintf("clickhouse: query canceled (%v): %v", e.Err, e.Cause)
+}
+
+// Unwrap returns the error of the context, so errors.Is(err, context.DeadlineExceeded) reports a client timeout.
+func (e *CanceledError) Unwrap() error {
+	return e.Err
+}
+
+// IsServerTimeout reports whether the server stopped the query because it exceeded max_execution_time,
+// either set explicitly or translated from the context deadline.
+func IsServerTimeout(err error) bool {
+	var exception *Exception
+	return errors.As(err, &exception) && exception.Code == codeTimeoutExceeded
+}
+
+// withDeadline puts the time left until the context deadline, less the deadline margin, into the
+// max_execution_time setting of the query, so the server stops the query before the client cancels it.
+// The setting is left as it is when it was already set for the query, when the one from the DSN is
+// shorter or when less than a second is left (the setting is in whole seconds and 0 means no limit).
+func (ch *clickhouse) withDeadline(ctx context.Context) context.Context {
+	deadline, ok := ctx.Deadline()
+	if !ok || ch.deadlineMargin < 0 {
+		return ctx
+	}
+	if settings, ok := ctx.Value(querySettingsKey).(contextSettings); ok {
+		if _, found := settings["max_execution_time"]; found {
+			return ctx
+		}
+	}
+	timeout := (time.Until(deadline) - ch.deadlineMargin).Truncate(time.Second)
+	if timeout < time.Second || (ch.maxExecutionTime > 0 && ch.maxExecutionTime <= timeout) {
+		return ctx
+	}
+	return withSettings(ctx, contextSettings{"max_execution_time": timeout})
+}
+
+// queryError returns a CanceledError for an error caused by the cancellation of the query,
+// the exceptions of the server other than query cancellations are returned as they are.
+func queryError(ctx context.Context, err error) error {
+	var (
+		exception *Exception
+		canceled  *CanceledError
+	)
+	switch {
+	case err == nil, ctx == nil, ctx.Err() == nil, errors.As(err, &canceled):
+		return err
+	case errors.As(err, &exception) && exception.Code != codeQueryWasCancelled:
+		return err
+	}
+	return &CanceledError{
+		Err:   ctx.Err(),
+		Cause: err,
+	}
+}
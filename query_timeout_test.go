@@ -0,0 +1,65 @@
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WithDeadline(t *testing.T) {
+	maxExecutionTime := func(ctx context.Context) interface{} {
+		settings, _ := ctx.Value(querySettingsKey).(contextSettings)
+		return settings["max_execution_time"]
+	}
+	ch := &clickhouse{deadlineMargin: DefaultDeadlineMargin}
+	assert.Nil(t, maxExecutionTime(ch.withDeadline(context.Background())))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10500*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, 9*time.Second, maxExecutionTime(ch.withDeadline(ctx)))
+	// the settings already in the context are kept
+	settings := ch.withDeadline(withSettings(ctx, contextSettings{"max_block_size": 10}))
+	assert.Equal(t, 9*time.Second, maxExecutionTime(settings))
+	assert.Equal(t, 10, settings.Value(querySettingsKey).(contextSettings)["max_block_size"])
+
+	// the explicit setting is not overridden
+	assert.Equal(t, 60, maxExecutionTime(ch.withDeadline(withSettings(ctx, contextSettings{"max_execution_time": 60}))))
+
+	for _, ch := range []*clickhouse{
+		{deadlineMargin: -1},
+		{deadlineMargin: 10 * time.Second},
+		{deadlineMargin: time.Second, maxExecutionTime: 5 * time.Second},
+	} {
+		assert.Nil(t, maxExecutionTime(ch.withDeadline(ctx)))
+	}
+	assert.Equal(t, 10*time.Second, maxExecutionTime((&clickhouse{}).withDeadline(ctx)))
+	assert.Equal(t, 9*time.Second, maxExecutionTime((&clickhouse{deadlineMargin: time.Second, maxExecutionTime: time.Minute}).withDeadline(ctx)))
+}
+
+func Test_QueryError(t *testing.T) {
+	var (
+		timeout   = &Exception{Code: codeTimeoutExceeded, Message: "Timeout exceeded: elapsed 9.001 seconds, maximum: 9"}
+		cancelled = &Exception{Code: codeQueryWasCancelled, Message: "Query was cancelled"}
+		ctx, stop = context.WithCancel(context.Background())
+	)
+	assert.Equal(t, io.EOF, queryError(ctx, io.EOF))
+	assert.Equal(t, io.EOF, queryError(nil, io.EOF))
+	assert.NoError(t, queryError(ctx, nil))
+	stop()
+	err := queryError(ctx, io.EOF)
+	if assert.Error(t, err) {
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.False(t, IsConnectionError(err))
+		assert.Equal(t, "clickhouse: query canceled (context canceled): EOF", err.Error())
+		assert.True(t, queryError(ctx, err) == err)
+	}
+	assert.True(t, errors.Is(queryError(ctx, cancelled), context.Canceled))
+	assert.True(t, queryError(ctx, timeout) == error(timeout))
+	assert.True(t, IsServerTimeout(timeout))
+	assert.False(t, IsServerTimeout(cancelled))
+	assert.False(t, IsServerTimeout(err))
+}
@@ -0,0 +1,178 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+	bytesType   = reflect.TypeOf([]byte{})
+)
+
+// Iter iterates over the rows of a query as values of T.
+//
+//	iter, err := clickhouse.QueryIter[Example](ctx, db, "SELECT id, name FROM example")
+//	if err != nil {
+//		return err
+//	}
+//	defer iter.Close()
+//	for iter.Next() {
+//		example := iter.Value()
+//		...
+//	}
+//	return iter.Err()
+type Iter[T any] struct {
+	rows  *sql.Rows
+	scan  func(*T) error
+	value T
+	err   error
+}
+
+// QueryIter runs the query and returns an iterator over its rows as values of T.
+//
+// A struct (or pointer to struct) T is filled by matching the columns to its fields as by Select,
+// any other T, e.g. uint64, string, *int32 or []string, is scanned from the only column of the result.
+// Array columns are scanned into slices and Nullable columns into pointers, which are nil for NULL:
+// Array(Nullable(Int32)) into []*int32 and Array(Array(String)) into [][]string.
+func QueryIter[T any](ctx context.Context, conn Queryer, query string, args ...interface{}) (*Iter[T], error) {
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	scan, err := rowScanner[T](rows)
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+	return &Iter[T]{
+		rows: rows,
+		scan: scan,
+	}, nil
+}
+
+// Next scans the next row, it returns false at the end of the result or on error.
+func (iter *Iter[T]) Next() bool {
+	if iter.err != nil || !iter.rows.Next() {
+		return false
+	}
+	var value T
+	if iter.err = iter.scan(&value); iter.err != nil {
+		return false
+	}
+	iter.value = value
+	return true
+}
+
+// Value returns the current row.
+func (iter *Iter[T]) Value() T {
+	return iter.value
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (iter *Iter[T]) Err() error {
+	if iter.err != nil {
+		return iter.err
+	}
+	return iter.rows.Err()
+}
+
+// Close discards the rest of the result.
+func (iter *Iter[T]) Close() error {
+	return iter.rows.Close()
+}
+
+// QueryAll runs the query and returns all its rows as values of T, mapped as by QueryIter.
+func QueryAll[T any](ctx context.Context, conn Queryer, query string, args ...interface{}) ([]T, error) {
+	iter, err := QueryIter[T](ctx, conn, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	var values []T
+	for iter.Next() {
+		values = append(values, iter.Value())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// rowScanner returns the function scanning the current row into a T.
+func rowScanner[T any](rows *sql.Rows) (func(*T) error, error) {
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		typ      = reflect.TypeOf((*T)(nil)).Elem()
+		elemType = typ
+		isPtr    = false
+	)
+	if typ.Kind() == reflect.Ptr && isStructType(typ.Elem()) {
+		elemType, isPtr = typ.Elem(), true
+	}
+	if !isStructType(elemType) {
+		if len(columnTypes) != 1 {
+			return nil, fmt.Errorf("clickhouse: %s is scanned from a single column, the result has %d columns", typ, len(columnTypes))
+		}
+		if err := checkScanType(columnTypes[0].Name(), columnTypes[0].DatabaseTypeName(), columnTypes[0].ScanType(), typ); err != nil {
+			return nil, err
+		}
+		return func(dest *T) error {
+			return rows.Scan(dest)
+		}, nil
+	}
+	columns := make([]string, 0, len(columnTypes))
+	for _, column := range columnTypes {
+		columns = append(columns, column.Name())
+	}
+	indexes, err := getStructFields(elemType).mapping(columns)
+	if err != nil {
+		return nil, fmt.Errorf("%w (columns: %s)", err, strings.Join(columns, ", "))
+	}
+	for i, index := range indexes {
+		column := columnTypes[i]
+		if err := checkScanType(column.Name(), column.DatabaseTypeName(), column.ScanType(), elemType.FieldByIndex(index).Type); err != nil {
+			return nil, err
+		}
+	}
+	return func(dest *T) error {
+		value := reflect.ValueOf(dest).Elem()
+		if isPtr {
+			value.Set(reflect.New(elemType))
+			value = value.Elem()
+		}
+		return scanStruct(rows, value, indexes)
+	}, nil
+}
+
+// isStructType reports whether the columns are mapped to the fields of the type,
+// structs scanned as a whole such as time.Time and sql.Null* are not.
+func isStructType(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType && !reflect.PtrTo(t).Implements(scannerType)
+}
+
+// checkScanType checks that the values of Array and Tuple columns, which database/sql does not convert,
+// can be assigned to the destination. Other columns are converted by database/sql when they are scanned.
+func checkScanType(name, chType string, scanType, dest reflect.Type) error {
+	if scanType == nil || scanType.Kind() != reflect.Slice || scanType == bytesType {
+		return nil
+	}
+	if dest.Kind() == reflect.Interface || reflect.PtrTo(dest).Implements(scannerType) {
+		return nil
+	}
+	if dest.Kind() == reflect.Ptr {
+		dest = dest.Elem()
+	}
+	if scanType.AssignableTo(dest) {
+		return nil
+	}
+	return fmt.Errorf("clickhouse: column %s of type %s is scanned as %s, not %s", name, chType, scanType, dest)
+}
@@ -0,0 +1,55 @@
+package clickhouse
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_IsStructType(t *testing.T) {
+	for _, v := range []interface{}{structMapRow{}, struct{ ID uint64 }{}} {
+		assert.True(t, isStructType(reflect.TypeOf(v)), "%T", v)
+	}
+	for _, v := range []interface{}{time.Time{}, sql.NullString{}, uint64(0), "", []string{}, &structMapRow{}} {
+		assert.False(t, isStructType(reflect.TypeOf(v)), "%T", v)
+	}
+}
+
+func Test_CheckScanType(t *testing.T) {
+	var (
+		strings  = reflect.TypeOf([]string{})
+		nested   = reflect.TypeOf([][]string{})
+		nullable = reflect.TypeOf([]*int32{})
+	)
+	for _, test := range []struct {
+		scanType reflect.Type
+		dest     interface{}
+		valid    bool
+	}{
+		{reflect.TypeOf(uint64(0)), "", true}, // converted by database/sql
+		{reflect.TypeOf(""), []byte{}, true},
+		{bytesType, "", true},
+		{strings, []string{}, true},
+		{strings, &[]string{}, true},
+		{strings, []interface{}{}, false},
+		{strings, []int{}, false},
+		{strings, "", false},
+		{nested, [][]string{}, true},
+		{nested, []string{}, false},
+		{nullable, []*int32{}, true},
+		{nullable, []int32{}, false},
+	} {
+		dest := reflect.TypeOf(test.dest)
+		err := checkScanType("c", "T", test.scanType, dest)
+		if test.valid {
+			assert.NoError(t, err, "%s into %s", test.scanType, dest)
+		} else if assert.Error(t, err, "%s into %s", test.scanType, dest) {
+			assert.Contains(t, err.Error(), "clickhouse: column c of type T is scanned as "+test.scanType.String())
+		}
+	}
+	assert.NoError(t, checkScanType("c", "T", strings, reflect.TypeOf((*interface{})(nil)).Elem()))
+	assert.NoError(t, checkScanType("c", "T", strings, reflect.TypeOf(sql.NullString{})))
+}
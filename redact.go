@@ -0,0 +1,58 @@
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+)
+
+const redacted = "[REDACTED]"
+
+var (
+	dsnPasswordRe   = regexp.MustCompile(`([?&]password=)[^&#]*`)
+	dsnUserInfoRe   = regexp.MustCompile(`(//[^:/@?#]*:)[^@/?#]*@`)
+	queryPasswordRe = regexp.MustCompile(`(?i)(\bIDENTIFIED\s+(?:WITH\s+\w+\s+)?BY\s+|\bPASSWORD\s*=?\s*)'(?:[^'\\]|\\.)*'`)
+)
+
+// redactDSN removes the password from a DSN.
+func redactDSN(dsn string) string {
+	dsn = dsnPasswordRe.ReplaceAllString(dsn, "${1}"+redacted)
+	return dsnUserInfoRe.ReplaceAllString(dsn, "${1}"+redacted+"@")
+}
+
+// redactQuery removes passwords from statements like CREATE USER ... IDENTIFIED BY '...'.
+func redactQuery(query string) string {
+	return queryPasswordRe.ReplaceAllString(query, "${1}'"+redacted+"'")
+}
+
+// redactURLError strips the credentials from the DSN embedded into errors of url.Parse.
+func redactURLError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return &url.Error{
+			Op:  urlErr.Op,
+			URL: redactDSN(urlErr.URL),
+			Err: urlErr.Err,
+		}
+	}
+	return err
+}
+
+var queryTemplateKey key = "query_template"
+
+// withQueryTemplate keeps the query before the parameters were bound,
+// it is logged instead of the bound query when redact_params is enabled.
+func withQueryTemplate(ctx context.Context, template string) context.Context {
+	return context.WithValue(ctx, queryTemplateKey, template)
+}
+
+// loggedQuery returns the query as it can be written to the debug log.
+func (ch *clickhouse) loggedQuery(ctx context.Context, query string) string {
+	if ch.redactParams {
+		if template, ok := ctx.Value(queryTemplateKey).(string); ok {
+			query = template
+		}
+	}
+	return redactQuery(query)
+}
@@ -0,0 +1,93 @@
+package clickhouse
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultResultBufferBlocks is the number of result blocks received ahead of the rows being read.
+const DefaultResultBufferBlocks = 50
+
+// bufferedBytes is the total size of the result blocks buffered by all the connections.
+var bufferedBytes int64
+
+// BufferedBytes returns the total size of the result blocks received from the server
+// and not yet read, over all the connections, for monitoring the memory held by query results.
+func BufferedBytes() int64 {
+	return atomic.LoadInt64(&bufferedBytes)
+}
+
+// ResultBuffer limits the query result data received from the server ahead of the rows being read.
+// When a limit is reached the connection stops reading from the socket until the rows are read,
+// so the server is slowed down to the pace of the reader instead of filling the memory.
+// A block is buffered from the moment it is received until the reader moves on to the next block.
+type ResultBuffer struct {
+	// Blocks is the maximum number of blocks waiting to be read, values less than 1 use DefaultResultBufferBlocks.
+	Blocks int
+	// Bytes is the maximum total size of the buffered blocks (0 - no limit). A received block which
+	// does not fit is held back until enough blocks were read, a block larger than the limit
+	// until all the previous blocks were read.
+	Bytes int64
+}
+
+var resultBufferKey key = "result_buffer"
+
+// WithResultBuffer puts a result buffer policy into context, it overrides the policy from the DSN
+// for the queries executed with this context.
+func WithResultBuffer(ctx context.Context, buffer ResultBuffer) context.Context {
+	return context.WithValue(ctx, resultBufferKey, buffer)
+}
+
+func (ch *clickhouse) resultBufferPolicy(ctx context.Context) ResultBuffer {
+	policy := ch.resultBuffer
+	if buffer, ok := ctx.Value(resultBufferKey).(ResultBuffer); ok {
+		policy = buffer
+	}
+	if policy.Blocks < 1 {
+		policy.Blocks = DefaultResultBufferBlocks
+	}
+	return policy
+}
+
+// resultBuffer accounts the size of the blocks of a result, blocking the receiver while the limit is reached.
+type resultBuffer struct {
+	maxBytes int64
+	mutex    sync.Mutex
+	cond     *sync.Cond
+	bytes    int64
+}
+
+func newResultBuffer(maxBytes int64) *resultBuffer {
+	buffer := &resultBuffer{
+		maxBytes: maxBytes,
+	}
+	buffer.cond = sync.NewCond(&buffer.mutex)
+	return buffer
+}
+
+// acquire waits until the block of size bytes fits into the buffer, it returns whether it had to wait.
+// A block always fits into an empty buffer.
+func (buffer *resultBuffer) acquire(size int64) (waited bool) {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+	for buffer.maxBytes > 0 && buffer.bytes > 0 && buffer.bytes+size > buffer.maxBytes {
+		waited = true
+		buffer.cond.Wait()
+	}
+	buffer.bytes += size
+	atomic.AddInt64(&bufferedBytes, size)
+	return waited
+}
+
+// release removes the block of size bytes from the buffer.
+func (buffer *resultBuffer) release(size int64) {
+	if size == 0 {
+		return
+	}
+	buffer.mutex.Lock()
+	buffer.bytes -= size
+	atomic.AddInt64(&bufferedBytes, -size)
+	buffer.mutex.Unlock()
+	buffer.cond.Broadcast()
+}
@@ -0,0 +1,110 @@
+package clickhouse
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/lib/binary"
+	"github.com/ClickHouse/clickhouse-go/lib/column"
+	"github.com/ClickHouse/clickhouse-go/lib/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ResultBufferPolicy(t *testing.T) {
+	ch := &clickhouse{
+		resultBuffer: ResultBuffer{Blocks: 10, Bytes: 1 << 20},
+	}
+	ctx := context.Background()
+	assert.Equal(t, ResultBuffer{Blocks: 10, Bytes: 1 << 20}, ch.resultBufferPolicy(ctx))
+	assert.Equal(t, ResultBuffer{Blocks: DefaultResultBufferBlocks, Bytes: 100}, ch.resultBufferPolicy(WithResultBuffer(ctx, ResultBuffer{Bytes: 100})))
+	assert.Equal(t, ResultBuffer{Blocks: DefaultResultBufferBlocks}, (&clickhouse{}).resultBufferPolicy(ctx))
+}
+
+func Test_ResultBufferBackPressure(t *testing.T) {
+	var (
+		buffer   = newResultBuffer(100)
+		buffered = BufferedBytes()
+		acquired = make(chan bool)
+	)
+	assert.False(t, buffer.acquire(60))
+	assert.Equal(t, buffered+60, BufferedBytes())
+	go func() {
+		acquired <- buffer.acquire(50)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("the block must wait until the buffer has room")
+	case <-time.After(50 * time.Millisecond):
+	}
+	buffer.release(60)
+	select {
+	case waited := <-acquired:
+		assert.True(t, waited)
+	case <-time.After(time.Second):
+		t.Fatal("the block must be acquired once the buffer has room")
+	}
+	buffer.release(50)
+	// a block larger than the limit fits into the empty buffer
+	assert.False(t, buffer.acquire(500))
+	buffer.release(500)
+	assert.Equal(t, buffered, BufferedBytes())
+
+	unlimited := newResultBuffer(0)
+	assert.False(t, unlimited.acquire(1<<40))
+	unlimited.release(1 << 40)
+}
+
+func Test_RowsReleaseResultBuffer(t *testing.T) {
+	var (
+		buffered = BufferedBytes()
+		rows     = &rows{
+			ch:     &clickhouse{logf: func(string, ...interface{}) {}},
+			finish: func() {},
+			stream: make(chan *data.Block, 3),
+			buffer: newResultBuffer(0),
+		}
+		sizes []int64
+	)
+	for i := 0; i < 3; i++ {
+		block := receivedTestBlock(t, int64(i))
+		sizes = append(sizes, block.ReadSize())
+		rows.buffer.acquire(block.ReadSize())
+		rows.stream <- block
+	}
+	close(rows.stream)
+	assert.Equal(t, buffered+sizes[0]+sizes[1]+sizes[2], BufferedBytes())
+	dest := make([]driver.Value, 1)
+	if assert.NoError(t, rows.Next(dest)) {
+		assert.Equal(t, int64(0), dest[0])
+		assert.Equal(t, buffered+sizes[0]+sizes[1]+sizes[2], BufferedBytes(), "the block being read is buffered")
+	}
+	if assert.NoError(t, rows.Next(dest)) {
+		assert.Equal(t, int64(1), dest[0])
+		assert.Equal(t, buffered+sizes[1]+sizes[2], BufferedBytes())
+	}
+	assert.NoError(t, rows.Close())
+	assert.Equal(t, buffered, BufferedBytes())
+}
+
+func receivedTestBlock(t *testing.T, value int64) *data.Block {
+	col, err := column.Factory("value", "Int64", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := &data.Block{NumColumns: 1, Columns: []column.Column{col}}
+	if err := block.AppendRow([]driver.Value{value}); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := block.Write(&data.ServerInfo{}, binary.NewEncoder(&buf)); err != nil {
+		t.Fatal(err)
+	}
+	received := &data.Block{}
+	if err := received.Read(&data.ServerInfo{}, binary.NewDecoder(&buf)); err != nil {
+		t.Fatal(err)
+	}
+	return received
+}
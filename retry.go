@@ -0,0 +1,132 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/lib/data"
+)
+
+// RetryPolicy describes how read-only queries are retried when the connection
+// is lost before the server has returned any rows. Every retry is done on a
+// new connection opened using the connection_open_strategy, so with alt_hosts
+// the query may be retried on another replica.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values less than 2 disable retries.
+	MaxAttempts int
+	// Backoff is the delay before the first retry. It is doubled for every subsequent retry.
+	Backoff time.Duration
+	// MaxBackoff caps the delay between retries (0 - no limit).
+	MaxBackoff time.Duration
+	// Retryable reports whether a query failed with err may be retried.
+	// IsConnectionError is used when it is nil.
+	Retryable func(err error) bool
+}
+
+func (policy *RetryPolicy) retryable(err error) bool {
+	if policy.Retryable != nil {
+		return policy.Retryable(err)
+	}
+	return IsConnectionError(err)
+}
+
+// wait sleeps for the backoff after the given attempt, it returns false if ctx is done first.
+func (policy *RetryPolicy) wait(ctx context.Context, attempt int) bool {
+	delay := policy.backoff(attempt)
+	if delay <= 0 {
+		return true
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (policy *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := policy.Backoff
+	for i := 1; i < attempt && (policy.MaxBackoff == 0 || delay < policy.MaxBackoff); i++ {
+		delay *= 2
+	}
+	if policy.MaxBackoff != 0 && delay > policy.MaxBackoff {
+		delay = policy.MaxBackoff
+	}
+	return delay
+}
+
+var retryPolicyKey key = "retry_policy"
+
+// WithRetryPolicy puts a retry policy into context, it overrides the policy from the DSN
+// for the queries executed with this context.
+func WithRetryPolicy(ctx context.Context, policy RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey, policy)
+}
+
+// IsConnectionError reports whether err is caused by a broken or refused
+// connection rather than by the server rejecting the query or by a read or write timeout.
+func IsConnectionError(err error) bool {
+	var (
+		netErr    net.Error
+		exception *Exception
+	)
+	switch {
+	case err == nil, errors.As(err, &exception):
+		return false
+	case errors.Is(err, driver.ErrBadConn),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.EPIPE):
+		return true
+	case errors.As(err, &netErr):
+		// a timeout means the server is slow rather than the connection lost, re-running the query would not help
+		return !netErr.Timeout()
+	}
+	return false
+}
+
+func (ch *clickhouse) getRetryPolicy(ctx context.Context) RetryPolicy {
+	if policy, ok := ctx.Value(retryPolicyKey).(RetryPolicy); ok {
+		return policy
+	}
+	return ch.retryPolicy
+}
+
+// queryMeta sends the query and reads the header block of the result.
+// Read-only queries are retried on a new connection according to the retry policy.
+func (ch *clickhouse) queryMeta(ctx context.Context, query string, externalTables []ExternalTable) (meta *data.Block, err error) {
+	var (
+		policy    = ch.getRetryPolicy(ctx)
+		retryable = policy.MaxAttempts > 1 && isReadOnly(query)
+	)
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			ch.conn.Close()
+			err = ch.dial()
+		}
+		if err == nil {
+			if err = ch.sendQuery(ctx, query, externalTables); err == nil {
+				if meta, err = ch.readMeta(); err == nil {
+					return meta, nil
+				}
+			}
+		}
+		if !retryable || attempt >= policy.MaxAttempts || ctx.Err() != nil || !policy.retryable(err) {
+			return nil, err
+		}
+		ch.logf("[retry] attempt %d of %d failed: %v", attempt, policy.MaxAttempts, err)
+		if !policy.wait(ctx, attempt) {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package clickhouse
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{
+		Backoff:    100 * time.Millisecond,
+		MaxBackoff: time.Second,
+	}
+	for attempt, expected := range map[int]time.Duration{
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		3: 400 * time.Millisecond,
+		4: 800 * time.Millisecond,
+		5: time.Second,
+		9: time.Second,
+	} {
+		assert.Equal(t, expected, policy.backoff(attempt), attempt)
+	}
+}
+
+func Test_IsConnectionError(t *testing.T) {
+	assert.True(t, IsConnectionError(driver.ErrBadConn))
+	assert.True(t, IsConnectionError(io.EOF))
+	assert.True(t, IsConnectionError(fmt.Errorf("read: %w", io.ErrUnexpectedEOF)))
+	assert.False(t, IsConnectionError(nil))
+	assert.False(t, IsConnectionError(errors.New("some error")))
+	assert.False(t, IsConnectionError(&Exception{Code: 60, Message: "Table default.example doesn't exist."}))
+	assert.False(t, IsConnectionError(&net.OpError{Op: "read", Net: "tcp", Err: os.ErrDeadlineExceeded}))
+	assert.True(t, IsConnectionError(&net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}))
+}
+
+// Test_RedialCancel redials while the query is being canceled, as a retry does, run with -race.
+func Test_RedialCancel(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	ch := &clickhouse{
+		logf:   func(string, ...interface{}) {},
+		logger: log.New(io.Discard, "", 0),
+		auth:   staticCredentials{},
+		connOptions: connOptions{
+			hosts:        []string{listener.Addr().String()},
+			connTimeout:  time.Second,
+			readTimeout:  time.Second,
+			writeTimeout: time.Second,
+			logf:         func(string, ...interface{}) {},
+		},
+	}
+	assert.Error(t, ch.dial(), "the server closes the connection before the handshake")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			ch.cancel()
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		ch.dial()
+	}
+	<-done
+}
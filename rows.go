@@ -1,9 +1,11 @@
 package clickhouse
 
 import (
+	"context"
 	"database/sql/driver"
 	"fmt"
 	"io"
+	"math"
 	"reflect"
 	"sync"
 	"time"
@@ -15,6 +17,7 @@ import (
 
 type rows struct {
 	ch           *clickhouse
+	ctx          context.Context
 	err          error
 	mutex        sync.RWMutex
 	finish       func()
@@ -23,6 +26,8 @@ type rows struct {
 	totals       *data.Block
 	extremes     *data.Block
 	stream       chan *data.Block
+	buffer       *resultBuffer
+	received     int64 // size of the last block received from the stream
 	columns      []string
 	blockColumns []column.Column
 }
@@ -41,7 +46,7 @@ func (rows *rows) ColumnTypeDatabaseTypeName(idx int) string {
 
 func (rows *rows) Next(dest []driver.Value) error {
 	if rows.block == nil || int(rows.block.NumRows) <= rows.offset {
-		switch block, ok := <-rows.stream; true {
+		switch block, ok := rows.nextBlock(); true {
 		case !ok:
 			if err := rows.error(); err != nil {
 				return err
@@ -53,7 +58,7 @@ func (rows *rows) Next(dest []driver.Value) error {
 		}
 	}
 	for i := range dest {
-		dest[i] = rows.block.Values[i][rows.offset]
+		dest[i] = rows.block.Value(i, rows.offset)
 	}
 	rows.offset++
 	return nil
@@ -123,6 +128,9 @@ func (rows *rows) receiveData() error {
 			}
 			switch packet {
 			case protocol.ServerData:
+				if size := block.ReadSize(); rows.buffer.acquire(size) {
+					rows.ch.logf("[rows] result buffer was full, block of %d bytes resumed", size)
+				}
 				rows.stream <- block
 			case protocol.ServerTotals:
 				rows.totals = block
@@ -143,12 +151,26 @@ func (rows *rows) receiveData() error {
 func (rows *rows) Close() error {
 	rows.ch.logf("[rows] close")
 	rows.columns = nil
-	for range rows.stream {
+	rows.buffer.release(rows.received)
+	rows.received = 0
+	for block := range rows.stream {
+		rows.buffer.release(block.ReadSize())
 	}
 	rows.finish()
 	return nil
 }
 
+// nextBlock receives the next block from the stream, removing the previous one from the result buffer.
+func (rows *rows) nextBlock() (*data.Block, bool) {
+	rows.buffer.release(rows.received)
+	rows.received = 0
+	block, ok := <-rows.stream
+	if ok {
+		rows.received = block.ReadSize()
+	}
+	return block, ok
+}
+
 func (rows *rows) error() error {
 	rows.mutex.RLock()
 	defer rows.mutex.RUnlock()
@@ -156,6 +178,7 @@ func (rows *rows) error() error {
 }
 
 func (rows *rows) setError(err error) error {
+	err = queryError(rows.ctx, err)
 	rows.mutex.Lock()
 	rows.err = err
 	rows.mutex.Unlock()
@@ -167,6 +190,21 @@ func (rows *rows) ColumnTypeNullable(idx int) (nullable, ok bool) {
 	return ok, true
 }
 
+// ColumnTypeLength returns the length of String (math.MaxInt64, not limited) and FixedString columns.
+func (rows *rows) ColumnTypeLength(idx int) (length int64, ok bool) {
+	col := rows.blockColumns[idx]
+	if nullable, ok := col.(*column.Nullable); ok {
+		col = nullable.GetColumn()
+	}
+	switch col := col.(type) {
+	case *column.String:
+		return math.MaxInt64, true
+	case *column.FixedString:
+		return int64(col.GetLength()), true
+	}
+	return 0, false
+}
+
 func (rows *rows) ColumnTypePrecisionScale(idx int) (precision, scale int64, ok bool) {
 	decimalVal, ok := rows.blockColumns[idx].(*column.Decimal)
 	if !ok {
@@ -0,0 +1,187 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ScriptResult is the result of a statement executed by ExecScript.
+type ScriptResult struct {
+	Statement string
+	Result    driver.Result
+	Elapsed   time.Duration
+}
+
+// ScriptError is returned by ExecScript when a statement fails, the statements after it are not executed.
+type ScriptError struct {
+	// Index is the index of the failed statement in the script, starting at 0.
+	Index     int
+	Statement string
+	Err       error
+}
+
+func (e *ScriptError) Error() string {
+	return fmt.Sprintf("clickhouse: script statement %d: %v", e.Index, e.Err)
+}
+
+func (e *ScriptError) Unwrap() error {
+	return e.Err
+}
+
+// ExecScript splits the script into statements with SplitStatements and executes them one by one
+// on the connection. It returns the results of the executed statements, and a *ScriptError with
+// the index of the statement which failed, if any.
+//
+// The statements are sent as they are written, so they cannot have arguments, and INSERT statements
+// must have their data inline (INSERT ... VALUES, INSERT ... SELECT).
+// With database/sql the method is available through the driver connection:
+//
+//	conn.Raw(func(driverConn interface{}) error {
+//		_, err := driverConn.(clickhouse.Clickhouse).ExecScript(ctx, script)
+//		return err
+//	})
+func (ch *clickhouse) ExecScript(ctx context.Context, script string) ([]ScriptResult, error) {
+	statements, err := SplitStatements(script)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]ScriptResult, 0, len(statements))
+	for i, statement := range statements {
+		started := time.Now()
+		result, err := ch.execStatement(ctx, statement)
+		if err != nil {
+			return results, &ScriptError{
+				Index:     i,
+				Statement: statement,
+				Err:       err,
+			}
+		}
+		results = append(results, ScriptResult{
+			Statement: statement,
+			Result:    result,
+			Elapsed:   time.Since(started),
+		})
+	}
+	return results, nil
+}
+
+func (ch *clickhouse) execStatement(ctx context.Context, statement string) (driver.Result, error) {
+	switch {
+	case ch.conn.closed:
+		return nil, driver.ErrBadConn
+	case ch.batch != nil:
+		return nil, ErrBatchInProgress
+	case ch.block != nil:
+		return nil, ErrLimitDataRequestInTx
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ch.logf("[exec script] %s", redactQuery(statement))
+	finish := ch.watchCancel(ctx)
+	defer finish()
+	stmt := &stmt{
+		ch:    ch,
+		query: statement,
+	}
+	return stmt.execContext(ctx, nil)
+}
+
+// SplitStatements splits a SQL script into the statements separated by semicolons. Semicolons
+// in string literals, quoted identifiers, comments (-- and /* */) and heredocs ($$...$$, $tag$...$tag$)
+// do not separate statements. The statements are trimmed and the ones with only comments are skipped.
+func SplitStatements(script string) ([]string, error) {
+	var (
+		statements []string
+		start      int
+		hasCode    bool
+	)
+	for i := 0; i < len(script); {
+		switch c := script[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			end := quotedStringEnd(script[i:])
+			if end == -1 {
+				return nil, fmt.Errorf("clickhouse: unterminated %c quote at offset %d", c, i)
+			}
+			i, hasCode = i+end, true
+		case strings.HasPrefix(script[i:], "--"):
+			if end := strings.IndexByte(script[i:], '\n'); end != -1 {
+				i += end + 1
+			} else {
+				i = len(script)
+			}
+		case strings.HasPrefix(script[i:], "/*"):
+			end := commentEnd(script[i:])
+			if end == -1 {
+				return nil, fmt.Errorf("clickhouse: unterminated comment at offset %d", i)
+			}
+			i += end
+		case c == '$':
+			tag := heredocTag(script[i:])
+			if tag == "" {
+				i, hasCode = i+1, true
+				continue
+			}
+			end := strings.Index(script[i+len(tag):], tag)
+			if end == -1 {
+				return nil, fmt.Errorf("clickhouse: unterminated heredoc %s at offset %d", tag, i)
+			}
+			i, hasCode = i+len(tag)+end+len(tag), true
+		case c == ';':
+			if hasCode {
+				statements = append(statements, strings.TrimSpace(script[start:i]))
+			}
+			i, start, hasCode = i+1, i+1, false
+		default:
+			if !isSpace(c) {
+				hasCode = true
+			}
+			i++
+		}
+	}
+	if hasCode {
+		statements = append(statements, strings.TrimSpace(script[start:]))
+	}
+	return statements, nil
+}
+
+// commentEnd returns the index after the end of the (nested) comment at the start of str, -1 if it is not terminated.
+func commentEnd(str string) int {
+	var depth int
+	for i := 0; i+1 < len(str); i++ {
+		switch str[i : i+2] {
+		case "/*":
+			depth, i = depth+1, i+1
+		case "*/":
+			if depth, i = depth-1, i+1; depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
+
+// heredocTag returns the opening tag of the heredoc at the start of str, e.g. $$ or $sql$, empty if there is none.
+func heredocTag(str string) string {
+	for i := 1; i < len(str); i++ {
+		switch c := str[i]; {
+		case c == '$':
+			return str[:i+1]
+		case c == '_', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9' && i > 1:
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+func isSpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\r', '\f', '\v':
+		return true
+	}
+	return false
+}
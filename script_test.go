@@ -0,0 +1,43 @@
+package clickhouse
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SplitStatements(t *testing.T) {
+	for script, expected := range map[string][]string{
+		"":                                       nil,
+		"  ;\n; -- only a comment\n":             nil,
+		"SELECT 1":                               {"SELECT 1"},
+		"SELECT 1; SELECT 2;\n":                  {"SELECT 1", "SELECT 2"},
+		"SELECT 'a;b', \"c;d\", `e;f`; SELECT 2": {"SELECT 'a;b', \"c;d\", `e;f`", "SELECT 2"},
+		`SELECT 'it\'s;', 'it''s;'; SELECT 2`:    {`SELECT 'it\'s;', 'it''s;'`, "SELECT 2"},
+		"-- drop; it\nDROP TABLE t; /* a; /* nested; */ comment; */ SELECT 1": {
+			"-- drop; it\nDROP TABLE t",
+			"/* a; /* nested; */ comment; */ SELECT 1",
+		},
+		"SELECT $$a;b$$; SELECT $sql$SELECT 1; $$ $sql$, $1": {"SELECT $$a;b$$", "SELECT $sql$SELECT 1; $$ $sql$, $1"},
+		"SELECT 1 -- no semicolon at the end":                {"SELECT 1 -- no semicolon at the end"},
+	} {
+		statements, err := SplitStatements(script)
+		if assert.NoError(t, err, script) {
+			assert.Equal(t, expected, statements, script)
+		}
+	}
+	for _, script := range []string{"SELECT 'a;", "SELECT `a", "SELECT 1 /* a /* b */", "SELECT $$a;"} {
+		_, err := SplitStatements(script)
+		assert.Error(t, err, script)
+	}
+}
+
+func Test_ScriptError(t *testing.T) {
+	err := &ScriptError{Index: 2, Statement: "SELECT x", Err: &Exception{Code: 47, Message: "Missing columns: 'x'"}}
+	assert.Equal(t, "clickhouse: script statement 2: code: 47, message: Missing columns: 'x'", err.Error())
+	var exception *Exception
+	if assert.True(t, errors.As(err, &exception)) {
+		assert.Equal(t, int32(47), exception.Code)
+	}
+}
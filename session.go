@@ -0,0 +1,212 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+var ErrSessionClosed = errors.New("session is closed")
+
+const tableNamePattern = "(`(?:[^`\\\\]|\\\\.)*`|\"(?:[^\"\\\\]|\\\\.)*\"|[A-Za-z_][A-Za-z0-9_]*)"
+
+var (
+	createTemporaryTableRe = regexp.MustCompile(`(?is)^\s*CREATE\s+TEMPORARY\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?` + tableNamePattern)
+	dropTableRe            = regexp.MustCompile(`(?is)^\s*DROP\s+(?:TEMPORARY\s+)?TABLE\s+(?:IF\s+EXISTS\s+)?` + tableNamePattern + `\s*;?\s*$`)
+)
+
+// Session runs queries on a single connection, so the temporary tables created with
+// CREATE TEMPORARY TABLE can be used by the following queries. The temporary tables
+// created with Exec are dropped when the session is closed, before the connection is reused.
+//
+//	conn, err := db.Conn(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	defer conn.Close()
+//	session, err := clickhouse.NewSession(conn)
+//	if err != nil {
+//		return err
+//	}
+//	defer session.Close()
+//	if err := session.Exec(ctx, "CREATE TEMPORARY TABLE ids (id UInt64)"); err != nil {
+//		return err
+//	}
+//	...
+type Session struct {
+	conn   *sql.Conn   // the connection of a session started with NewSession
+	direct *clickhouse // the connection of a session started with NewDirectSession
+	tables []string
+	closed bool
+}
+
+// NewSession starts a session on the connection. Every call of the session runs within conn.Raw,
+// so the connection is not used concurrently by database/sql. The connection is not closed by the session.
+func NewSession(conn *sql.Conn) (*Session, error) {
+	session := &Session{conn: conn}
+	if err := session.raw(func(*clickhouse) error { return nil }); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// NewDirectSession starts a session on a connection opened with OpenDirect.
+// The connection is not closed by the session.
+func NewDirectSession(conn Clickhouse) (*Session, error) {
+	ch, ok := conn.(*clickhouse)
+	if !ok {
+		return nil, fmt.Errorf("clickhouse: session on an unsupported connection %T", conn)
+	}
+	return &Session{direct: ch}, nil
+}
+
+// raw runs fn with the driver connection of the session, the connection must not be used after fn returns.
+func (session *Session) raw(fn func(ch *clickhouse) error) error {
+	if session.conn == nil {
+		return fn(session.direct)
+	}
+	return session.conn.Raw(func(driverConn interface{}) error {
+		ch, ok := driverConn.(*clickhouse)
+		if !ok {
+			return fmt.Errorf("clickhouse: session on an unsupported connection %T", driverConn)
+		}
+		return fn(ch)
+	})
+}
+
+// Exec executes the query, the arguments are bound into the query as with database/sql.
+// The temporary tables created by the query are dropped when the session is closed.
+func (session *Session) Exec(ctx context.Context, query string, args ...interface{}) error {
+	if session.closed {
+		return ErrSessionClosed
+	}
+	err := session.raw(func(ch *clickhouse) error {
+		named, err := namedValues(ch, args)
+		if err != nil {
+			return err
+		}
+		_, err = ch.ExecContext(ctx, query, named)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	switch {
+	case createTemporaryTableRe.MatchString(query):
+		session.addTable(createTemporaryTableRe.FindStringSubmatch(query)[1])
+	case dropTableRe.MatchString(query):
+		session.removeTable(dropTableRe.FindStringSubmatch(query)[1])
+	}
+	return nil
+}
+
+// Query runs the query as QueryBlocks and calls fn for every block of its result.
+// The iteration stops at the first error returned by fn, the rest of the result is discarded.
+func (session *Session) Query(ctx context.Context, query string, fn func(block *ResultBlock) error, args ...interface{}) error {
+	if session.closed {
+		return ErrSessionClosed
+	}
+	return session.raw(func(ch *clickhouse) error {
+		blocks, err := ch.QueryBlocks(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer blocks.Close()
+		for blocks.Next() {
+			if err := fn(blocks.Block()); err != nil {
+				return err
+			}
+		}
+		return blocks.Err()
+	})
+}
+
+// Batch starts an insert on the session connection, e.g. into a temporary table, and calls fn
+// to append the rows. The batch is sent when fn returns nil and aborted when it returns an error.
+func (session *Session) Batch(ctx context.Context, query string, fn func(batch Batch) error) error {
+	if session.closed {
+		return ErrSessionClosed
+	}
+	return session.raw(func(ch *clickhouse) error {
+		batch, err := ch.PrepareBatch(ctx, query)
+		if err != nil {
+			return err
+		}
+		if err := fn(batch); err != nil {
+			if ch.batch == batch {
+				batch.Abort()
+			}
+			return err
+		}
+		return batch.Send()
+	})
+}
+
+// TemporaryTables returns the names of the temporary tables created with Exec and not dropped yet.
+func (session *Session) TemporaryTables() []string {
+	return append([]string(nil), session.tables...)
+}
+
+// Close drops the temporary tables created with Exec. The tables which could not be dropped
+// are dropped by the server when the connection is closed.
+func (session *Session) Close() error {
+	if session.closed {
+		return nil
+	}
+	session.closed = true
+	if len(session.tables) == 0 {
+		return nil
+	}
+	var firstErr error
+	err := session.raw(func(ch *clickhouse) error {
+		for _, table := range session.tables {
+			if ch.conn.closed {
+				break
+			}
+			if _, err := ch.ExecContext(context.Background(), "DROP TEMPORARY TABLE IF EXISTS "+table, nil); err != nil {
+				ch.logf("[session] drop temporary table %s: %v", table, err)
+				if firstErr == nil {
+					firstErr = err
+				}
+			}
+		}
+		return nil
+	})
+	session.tables = nil
+	if err != nil {
+		return err
+	}
+	return firstErr
+}
+
+func namedValues(ch *clickhouse, args []interface{}) ([]driver.NamedValue, error) {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+		if err := ch.CheckNamedValue(&named[i]); err != nil {
+			return nil, err
+		}
+	}
+	return named, nil
+}
+
+func (session *Session) addTable(table string) {
+	for _, t := range session.tables {
+		if t == table {
+			return
+		}
+	}
+	session.tables = append(session.tables, table)
+}
+
+func (session *Session) removeTable(table string) {
+	for i, t := range session.tables {
+		if t == table {
+			session.tables = append(session.tables[:i], session.tables[i+1:]...)
+			return
+		}
+	}
+}
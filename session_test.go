@@ -0,0 +1,41 @@
+package clickhouse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SessionTemporaryTableRe(t *testing.T) {
+	for query, table := range map[string]string{
+		"CREATE TEMPORARY TABLE ids (id UInt64)":                      "ids",
+		"\n\tcreate temporary table if not exists ids_2 (id UInt64)":  "ids_2",
+		"CREATE TEMPORARY TABLE `my ids` (id UInt64) ENGINE = Memory": "`my ids`",
+		`CREATE TEMPORARY TABLE "ids" AS SELECT 1`:                    `"ids"`,
+	} {
+		if match := createTemporaryTableRe.FindStringSubmatch(query); assert.NotNil(t, match, query) {
+			assert.Equal(t, table, match[1], query)
+		}
+	}
+	assert.False(t, createTemporaryTableRe.MatchString("CREATE TABLE ids (id UInt64) ENGINE = Memory"))
+	for query, table := range map[string]string{
+		"DROP TABLE ids": "ids",
+		"drop temporary table if exists `my ids`;": "`my ids`",
+	} {
+		if match := dropTableRe.FindStringSubmatch(query); assert.NotNil(t, match, query) {
+			assert.Equal(t, table, match[1], query)
+		}
+	}
+	assert.False(t, dropTableRe.MatchString("DROP TABLE db.ids"))
+}
+
+func Test_SessionTables(t *testing.T) {
+	session := &Session{}
+	session.addTable("a")
+	session.addTable("b")
+	session.addTable("a")
+	assert.Equal(t, []string{"a", "b"}, session.TemporaryTables())
+	session.removeTable("a")
+	session.removeTable("c")
+	assert.Equal(t, []string{"b"}, session.TemporaryTables())
+}
@@ -0,0 +1,260 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ClickHouse/clickhouse-go/lib/cityhash102"
+)
+
+// ShardKeyFunc returns the sharding key of a row, the values are in the order of the insert columns.
+type ShardKeyFunc func(row []interface{}) (uint64, error)
+
+// CityHash64Key returns the sharding key cityHash64(column) for the column with the given index,
+// computed as by the server: strings and []byte are hashed with CityHash v1.0.2 and numbers
+// with intHash64 of their bits.
+func CityHash64Key(column int) ShardKeyFunc {
+	return func(row []interface{}) (uint64, error) {
+		if column < 0 || column >= len(row) {
+			return 0, fmt.Errorf("sharding key: column index %d out of range (columns: %d)", column, len(row))
+		}
+		return cityHash64(row[column])
+	}
+}
+
+func cityHash64(v interface{}) (uint64, error) {
+	switch v := v.(type) {
+	case string:
+		return cityhash102.CityHash64([]byte(v), uint32(len(v))), nil
+	case []byte:
+		return cityhash102.CityHash64(v, uint32(len(v))), nil
+	case int8:
+		return intHash64(uint64(uint8(v))), nil
+	case int16:
+		return intHash64(uint64(uint16(v))), nil
+	case int32:
+		return intHash64(uint64(uint32(v))), nil
+	case int64:
+		return intHash64(uint64(v)), nil
+	case int:
+		return intHash64(uint64(v)), nil
+	case uint8:
+		return intHash64(uint64(v)), nil
+	case uint16:
+		return intHash64(uint64(v)), nil
+	case uint32:
+		return intHash64(uint64(v)), nil
+	case uint64:
+		return intHash64(v), nil
+	case uint:
+		return intHash64(uint64(v)), nil
+	case float32:
+		return intHash64(uint64(math.Float32bits(v))), nil
+	case float64:
+		return intHash64(math.Float64bits(v)), nil
+	}
+	return 0, fmt.Errorf("sharding key: unsupported type %T", v)
+}
+
+// intHash64 is the hash of numbers in cityHash64 (the MurmurHash3 finalizer).
+func intHash64(x uint64) uint64 {
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// ShardedInsertOptions configure a ShardedInsert.
+type ShardedInsertOptions struct {
+	// DSN holds the connection options of the shards, its host and alt_hosts are replaced by the hosts of every shard.
+	DSN string
+	// Shards maps the shard number, in the order of the cluster configuration, to the addresses (host:port)
+	// of its replicas. The replicas are used as alt_hosts of each other.
+	Shards map[int][]string
+	// Weights of the shards as in the cluster configuration, the default weight is 1.
+	Weights map[int]int
+	// Key returns the sharding key of a row, e.g. CityHash64Key(0).
+	Key ShardKeyFunc
+	// Loader configures the BulkLoader of every shard, Progress is called with the totals of a shard.
+	Loader BulkLoaderOptions
+}
+
+// ShardedInsert inserts rows directly into the local tables of the shards of a cluster,
+// instead of sending all the rows to a Distributed table which forwards them to the shards.
+// A row goes to the shard selected as by the Distributed engine: the remainder of the sharding key
+// divided by the total weight of the shards picks the shard in the order of the shard numbers.
+type ShardedInsert struct {
+	key    ShardKeyFunc
+	shards []*insertShard
+	slots  []int // index of the shard for every unit of the total weight
+}
+
+type insertShard struct {
+	number int
+	db     *sql.DB
+	loader *BulkLoader
+}
+
+// NewShardedInsert opens the connection pools of the shards for the insert query, an INSERT
+// statement of the local table without the VALUES part, e.g. "INSERT INTO example_local (a, b)".
+func NewShardedInsert(query string, options ShardedInsertOptions) (*ShardedInsert, error) {
+	switch {
+	case options.Key == nil:
+		return nil, errors.New("sharded insert: sharding key function is required")
+	case len(options.Shards) == 0:
+		return nil, errors.New("sharded insert: no shards")
+	}
+	numbers := make([]int, 0, len(options.Shards))
+	for number := range options.Shards {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+	insert := &ShardedInsert{
+		key: options.Key,
+	}
+	for i, number := range numbers {
+		weight, found := options.Weights[number]
+		if !found {
+			weight = 1
+		}
+		if weight <= 0 {
+			insert.Close()
+			return nil, fmt.Errorf("sharded insert: invalid weight %d of shard %d", weight, number)
+		}
+		dsn, err := shardDSN(options.DSN, options.Shards[number])
+		if err != nil {
+			insert.Close()
+			return nil, fmt.Errorf("sharded insert: shard %d: %w", number, err)
+		}
+		db, err := sql.Open("clickhouse", dsn)
+		if err != nil {
+			insert.Close()
+			return nil, fmt.Errorf("sharded insert: shard %d: %w", number, err)
+		}
+		insert.shards = append(insert.shards, &insertShard{
+			number: number,
+			db:     db,
+			loader: NewBulkLoader(db, query, options.Loader),
+		})
+		for w := 0; w < weight; w++ {
+			insert.slots = append(insert.slots, i)
+		}
+	}
+	return insert, nil
+}
+
+func shardDSN(dsn string, hosts []string) (string, error) {
+	if len(hosts) == 0 {
+		return "", errors.New("no hosts")
+	}
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", redactURLError(err)
+	}
+	query := u.Query()
+	query.Del("alt_hosts")
+	if len(hosts) > 1 {
+		query.Set("alt_hosts", strings.Join(hosts[1:], ","))
+	}
+	u.Host, u.RawQuery = hosts[0], query.Encode()
+	return u.String(), nil
+}
+
+// shard returns the index of the shard of the row.
+func (insert *ShardedInsert) shard(row []interface{}) (int, error) {
+	key, err := insert.key(row)
+	if err != nil {
+		return 0, err
+	}
+	return insert.slots[key%uint64(len(insert.slots))], nil
+}
+
+// Load inserts the rows returned by next until it returns io.EOF, routing every row to its shard.
+// The shards are loaded in parallel, each over the connections of its own pool, and the first
+// error cancels the load of all the shards. It returns the stats of the shards by shard number.
+//
+// The load is not atomic: blocks sent to the shards before an error are persisted.
+// As for BulkLoader, a context carrying a batch ID is rejected with ErrBulkLoadBatchID.
+func (insert *ShardedInsert) Load(ctx context.Context, next func() ([]interface{}, error)) (map[int]BulkLoadStats, error) {
+	if hasBatchID(ctx) {
+		return nil, ErrBulkLoadBatchID
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		mutex    sync.Mutex
+		firstErr error
+		stats    = make(map[int]BulkLoadStats, len(insert.shards))
+		channels = make([]chan []interface{}, len(insert.shards))
+	)
+	fail := func(err error) {
+		mutex.Lock()
+		if firstErr == nil {
+			firstErr = err
+		}
+		mutex.Unlock()
+		cancel()
+	}
+	for i, shard := range insert.shards {
+		channels[i] = make(chan []interface{}, shard.loader.options.Workers)
+		wg.Add(1)
+		go func(shard *insertShard, rows <-chan []interface{}) {
+			defer wg.Done()
+			shardStats, err := shard.loader.LoadChannel(ctx, rows)
+			mutex.Lock()
+			stats[shard.number] = shardStats
+			mutex.Unlock()
+			if err != nil {
+				fail(fmt.Errorf("shard %d: %w", shard.number, err))
+			}
+		}(shard, channels[i])
+	}
+route:
+	for {
+		row, err := next()
+		switch {
+		case err == io.EOF:
+			break route
+		case err != nil:
+			fail(err)
+			break route
+		}
+		i, err := insert.shard(row)
+		if err != nil {
+			fail(err)
+			break route
+		}
+		select {
+		case channels[i] <- row:
+		case <-ctx.Done():
+			break route
+		}
+	}
+	for _, rows := range channels {
+		close(rows)
+	}
+	wg.Wait()
+	return stats, firstErr
+}
+
+// Close closes the connection pools of the shards.
+func (insert *ShardedInsert) Close() error {
+	var err error
+	for _, shard := range insert.shards {
+		if e := shard.db.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
@@ -0,0 +1,103 @@
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CityHash64Key(t *testing.T) {
+	key := CityHash64Key(1)
+	if v, err := key([]interface{}{1, ""}); assert.NoError(t, err) {
+		assert.Equal(t, uint64(11160318154034397263), v, "SELECT cityHash64('')")
+	}
+	for _, values := range [][]interface{}{
+		{"value", []byte("value")},
+		{int8(-1), uint8(255)},
+		{int32(-1), uint32(4294967295)},
+		{int64(-1), uint64(18446744073709551615)},
+		{42, uint64(42)},
+	} {
+		a, errA := cityHash64(values[0])
+		b, errB := cityHash64(values[1])
+		if assert.NoError(t, errA) && assert.NoError(t, errB) {
+			assert.Equal(t, a, b, "%T and %T", values[0], values[1])
+		}
+	}
+	assert.Equal(t, uint64(0), intHash64(0))
+	_, err := key([]interface{}{1})
+	assert.Error(t, err)
+	_, err = key([]interface{}{1, struct{}{}})
+	assert.Error(t, err)
+}
+
+func Test_ShardedInsertRouting(t *testing.T) {
+	_, err := NewShardedInsert("INSERT INTO example", ShardedInsertOptions{Shards: map[int][]string{1: {"127.0.0.1:9000"}}})
+	assert.Error(t, err, "no key")
+	key := func(row []interface{}) (uint64, error) {
+		return row[0].(uint64), nil
+	}
+	for _, shards := range []map[int][]string{nil, {1: {}}} {
+		_, err := NewShardedInsert("INSERT INTO example", ShardedInsertOptions{Shards: shards, Key: key})
+		assert.Error(t, err)
+	}
+	insert, err := NewShardedInsert("INSERT INTO example", ShardedInsertOptions{
+		DSN:     "tcp://127.0.0.1:9000?debug=true",
+		Shards:  map[int][]string{2: {"127.0.0.2:9000"}, 1: {"127.0.0.1:9000"}},
+		Weights: map[int]int{2: 2},
+		Key:     key,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer insert.Close()
+	for key, expected := range []int{0, 1, 1, 0, 1} {
+		if i, err := insert.shard([]interface{}{uint64(key)}); assert.NoError(t, err) {
+			assert.Equal(t, expected, i, "key %d", key)
+		}
+	}
+	assert.Equal(t, []int{1, 2}, []int{insert.shards[0].number, insert.shards[1].number})
+
+	// the error of the row source cancels the load of the shards
+	failed := errors.New("read failed")
+	_, err = insert.Load(context.Background(), func() ([]interface{}, error) {
+		return nil, failed
+	})
+	assert.True(t, errors.Is(err, failed))
+}
+
+func Test_ShardDSN(t *testing.T) {
+	dsn, err := shardDSN("tcp://127.0.0.1:9000?alt_hosts=127.0.0.2:9000&username=user", []string{"host1:9000", "host2:9000", "host3:9000"})
+	if assert.NoError(t, err) {
+		u, _ := url.Parse(dsn)
+		assert.Equal(t, "host1:9000", u.Host)
+		assert.Equal(t, "host2:9000,host3:9000", u.Query().Get("alt_hosts"))
+		assert.Equal(t, "user", u.Query().Get("username"))
+	}
+	if dsn, err := shardDSN("tcp://127.0.0.1:9000?alt_hosts=127.0.0.2:9000", []string{"host1:9000"}); assert.NoError(t, err) {
+		assert.Equal(t, "tcp://host1:9000", dsn)
+	}
+	_, err = shardDSN("tcp://127.0.0.1:9000", nil)
+	assert.Error(t, err)
+}
+
+func Test_ShardedInsertBatchID(t *testing.T) {
+	insert, err := NewShardedInsert("INSERT INTO example_local", ShardedInsertOptions{
+		DSN:    "tcp://?timeout=0.1",
+		Shards: map[int][]string{1: {"127.0.0.1:1"}, 2: {"127.0.0.1:2"}},
+		Key:    CityHash64Key(0),
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer insert.Close()
+	_, err = insert.Load(WithBatchID(context.Background(), "import"), func() ([]interface{}, error) {
+		t.Fatal("no row must be read")
+		return nil, io.EOF
+	})
+	assert.Equal(t, ErrBulkLoadBatchID, err)
+}
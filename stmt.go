@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"database/sql/driver"
+	"time"
 	"unicode"
 
 	"github.com/ClickHouse/clickhouse-go/lib/data"
@@ -12,9 +13,9 @@ import (
 type stmt struct {
 	ch       *clickhouse
 	query    string
-	counter  int
 	numInput int
 	isInsert bool
+	started  time.Time // when the first row of the current insert block was appended
 }
 
 var emptyResult = &result{}
@@ -52,12 +53,15 @@ func (stmt *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (dr
 
 func (stmt *stmt) execContext(ctx context.Context, args []driver.Value) (driver.Result, error) {
 	if stmt.isInsert {
-		stmt.counter++
+		if stmt.started.IsZero() {
+			stmt.started = time.Now()
+		}
 		if err := stmt.ch.block.AppendRow(args); err != nil {
 			return nil, err
 		}
-		if (stmt.counter % stmt.ch.blockSize) == 0 {
-			stmt.ch.logf("[exec] flush block")
+		if stmt.ch.isBlockFull(stmt.ch.block, stmt.started) {
+			stmt.ch.logf("[exec] flush block: rows=%d, bytes=%d", stmt.ch.block.NumRows, stmt.ch.block.Size())
+			stmt.started = time.Time{}
 			if err := stmt.ch.writeBlock(stmt.ch.block, ""); err != nil {
 				return nil, err
 			}
@@ -68,11 +72,11 @@ func (stmt *stmt) execContext(ctx context.Context, args []driver.Value) (driver.
 		return emptyResult, nil
 	}
 	query, externalTables := stmt.bind(convertOldArgs(args))
-	if err := stmt.ch.sendQuery(ctx, query, externalTables); err != nil {
-		return nil, err
+	if err := stmt.ch.sendQuery(withQueryTemplate(ctx, stmt.query), query, externalTables); err != nil {
+		return nil, queryError(ctx, err)
 	}
 	if err := stmt.ch.process(); err != nil {
-		return nil, err
+		return nil, queryError(ctx, err)
 	}
 	return emptyResult, nil
 }
@@ -88,19 +92,18 @@ func (stmt *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (d
 func (stmt *stmt) queryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
 	finish := stmt.ch.watchCancel(ctx)
 	query, externalTables := stmt.bind(args)
-	if err := stmt.ch.sendQuery(ctx, query, externalTables); err != nil {
-		finish()
-		return nil, err
-	}
-	meta, err := stmt.ch.readMeta()
+	meta, err := stmt.ch.queryMeta(withQueryTemplate(ctx, stmt.query), query, externalTables)
 	if err != nil {
 		finish()
-		return nil, err
+		return nil, queryError(ctx, err)
 	}
+	buffer := stmt.ch.resultBufferPolicy(ctx)
 	rows := rows{
 		ch:           stmt.ch,
+		ctx:          ctx,
 		finish:       finish,
-		stream:       make(chan *data.Block, 50),
+		stream:       make(chan *data.Block, buffer.Blocks),
+		buffer:       newResultBuffer(buffer.Bytes),
 		columns:      meta.ColumnNames(),
 		blockColumns: meta.Columns,
 	}
@@ -0,0 +1,239 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrStructExpected = errors.New("clickhouse: expected a struct or a pointer to struct")
+	ErrSliceExpected  = errors.New("clickhouse: expected a pointer to slice of structs")
+)
+
+// structFields is the reflection-cached mapping of a struct type to column names.
+// Fields are matched by the `ch:"column"` tag or, without a tag, by the field name.
+// Fields of embedded structs are promoted, fields tagged `ch:"-"` are ignored.
+type structFields struct {
+	typ     reflect.Type
+	indexes map[string][]int
+}
+
+var structFieldsCache sync.Map // map[reflect.Type]*structFields
+
+func getStructFields(t reflect.Type) *structFields {
+	if fields, found := structFieldsCache.Load(t); found {
+		return fields.(*structFields)
+	}
+	fields := &structFields{
+		typ:     t,
+		indexes: make(map[string][]int),
+	}
+	fields.collect(t, nil)
+	actual, _ := structFieldsCache.LoadOrStore(t, fields)
+	return actual.(*structFields)
+}
+
+func (fields *structFields) collect(t reflect.Type, parent []int) {
+	for i := 0; i < t.NumField(); i++ {
+		var (
+			field = t.Field(i)
+			tag   = field.Tag.Get("ch")
+			index = append(append(make([]int, 0, len(parent)+1), parent...), i)
+		)
+		if tag == "-" {
+			continue
+		}
+		if field.Anonymous && tag == "" {
+			if ft := field.Type; ft.Kind() == reflect.Struct || (ft.Kind() == reflect.Ptr && ft.Elem().Kind() == reflect.Struct) {
+				if ft.Kind() == reflect.Ptr {
+					if field.PkgPath != "" {
+						// a nil pointer to an unexported struct cannot be allocated
+						continue
+					}
+					ft = ft.Elem()
+				}
+				fields.collect(ft, index)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		name := field.Name
+		if tag != "" {
+			name = tag
+		}
+		// fields of the outer struct take precedence over the promoted ones
+		if current, found := fields.indexes[name]; !found || len(current) > len(index) {
+			fields.indexes[name] = index
+		}
+	}
+}
+
+// mapping returns the field indexes in the order of the columns.
+func (fields *structFields) mapping(columns []string) ([][]int, error) {
+	indexes := make([][]int, 0, len(columns))
+	for _, column := range columns {
+		index, found := fields.indexes[column]
+		if !found {
+			return nil, fmt.Errorf("clickhouse: column %s has no matching field in %s", column, fields.typ)
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
+}
+
+// insertMapping is like mapping, but also reports the fields without a matching column as an error.
+func (fields *structFields) insertMapping(columns []string) ([][]int, error) {
+	indexes, err := fields.mapping(columns)
+	if err != nil {
+		return nil, err
+	}
+	matched := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		matched[column] = true
+	}
+	for _, column := range fields.columns() {
+		if !matched[column] {
+			field := fields.typ.FieldByIndex(fields.indexes[column])
+			return nil, fmt.Errorf("clickhouse: field %s of %s has no matching column %s (columns: %s)", field.Name, fields.typ, column, strings.Join(columns, ", "))
+		}
+	}
+	return indexes, nil
+}
+
+// columns returns the column names of the fields in the order of the fields.
+func (fields *structFields) columns() []string {
+	columns := make([]string, 0, len(fields.indexes))
+	for column := range fields.indexes {
+		columns = append(columns, column)
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		a, b := fields.indexes[columns[i]], fields.indexes[columns[j]]
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	return columns
+}
+
+// fieldValue returns the value of the field, nil if it belongs to a nil embedded struct.
+func fieldValue(v reflect.Value, index []int) interface{} {
+	for _, i := range index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return nil
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v.Interface()
+}
+
+// fieldAddr returns the pointer to the field, allocating nil embedded structs.
+func fieldAddr(v reflect.Value, index []int) interface{} {
+	for _, i := range index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v.Addr().Interface()
+}
+
+func structValue(v interface{}) (reflect.Value, error) {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return reflect.Value{}, ErrStructExpected
+	}
+	return value, nil
+}
+
+// ScanStruct copies the columns of the current row into the fields of the struct pointed to by dest.
+// Nullable columns can be scanned into pointer fields, which are set to nil for NULL values.
+func ScanStruct(rows *sql.Rows, dest interface{}) error {
+	value := reflect.ValueOf(dest)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return ErrStructExpected
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	indexes, err := getStructFields(value.Elem().Type()).mapping(columns)
+	if err != nil {
+		return err
+	}
+	return scanStruct(rows, value.Elem(), indexes)
+}
+
+func scanStruct(rows *sql.Rows, value reflect.Value, indexes [][]int) error {
+	dest := make([]interface{}, len(indexes))
+	for i, index := range indexes {
+		dest[i] = fieldAddr(value, index)
+	}
+	return rows.Scan(dest...)
+}
+
+// Queryer is implemented by *sql.DB, *sql.Conn and *sql.Tx.
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Select runs the query and appends every row to the slice of structs (or pointers to structs) pointed to by dest.
+func Select(ctx context.Context, conn Queryer, dest interface{}, query string, args ...interface{}) error {
+	slice := reflect.ValueOf(dest)
+	if slice.Kind() != reflect.Ptr || slice.IsNil() || slice.Elem().Kind() != reflect.Slice {
+		return ErrSliceExpected
+	}
+	var (
+		elemType = slice.Elem().Type().Elem()
+		isPtr    = elemType.Kind() == reflect.Ptr
+	)
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return ErrSliceExpected
+	}
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	indexes, err := getStructFields(elemType).mapping(columns)
+	if err != nil {
+		return fmt.Errorf("%w (columns: %s)", err, strings.Join(columns, ", "))
+	}
+	for rows.Next() {
+		elem := reflect.New(elemType)
+		if err := scanStruct(rows, elem.Elem(), indexes); err != nil {
+			return err
+		}
+		if !isPtr {
+			elem = elem.Elem()
+		}
+		slice.Elem().Set(reflect.Append(slice.Elem(), elem))
+	}
+	return rows.Err()
+}
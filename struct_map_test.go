@@ -0,0 +1,81 @@
+package clickhouse
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type structMapBase struct {
+	ID      uint64 `ch:"id"`
+	Created string `ch:"created"`
+}
+
+type StructMapExtra struct {
+	Comment *string `ch:"comment"`
+}
+
+type structMapRow struct {
+	structMapBase
+	*StructMapExtra
+	Name     string `ch:"name"`
+	Created  string `ch:"created_at"`
+	Value    float64
+	Ignored  string `ch:"-"`
+	internal string
+}
+
+func Test_StructFieldsMapping(t *testing.T) {
+	fields := getStructFields(reflect.TypeOf(structMapRow{}))
+	if indexes, err := fields.mapping([]string{"name", "id", "Value", "created", "created_at", "comment"}); assert.NoError(t, err) {
+		assert.Equal(t, [][]int{{2}, {0, 0}, {4}, {0, 1}, {3}, {1, 0}}, indexes)
+	}
+	for _, column := range []string{"Ignored", "internal", "Name", "structMapBase"} {
+		_, err := fields.mapping([]string{"id", column})
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "column "+column+" has no matching field in clickhouse.structMapRow")
+		}
+	}
+	assert.True(t, fields == getStructFields(reflect.TypeOf(structMapRow{})), "fields must be cached")
+}
+
+func Test_StructFieldsInsertMapping(t *testing.T) {
+	fields := getStructFields(reflect.TypeOf(structMapRow{}))
+	columns := []string{"id", "created", "comment", "name", "created_at", "Value"}
+	assert.Equal(t, columns, fields.columns())
+	if indexes, err := fields.insertMapping(columns); assert.NoError(t, err) {
+		assert.Equal(t, [][]int{{0, 0}, {0, 1}, {1, 0}, {2}, {3}, {4}}, indexes)
+	}
+	_, err := fields.insertMapping(append(columns, "missing"))
+	assert.Error(t, err)
+	if _, err := fields.insertMapping([]string{"id", "created", "name", "created_at", "Value"}); assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "field Comment of clickhouse.structMapRow has no matching column comment")
+	}
+}
+
+func Test_StructFieldValues(t *testing.T) {
+	var (
+		comment = "comment"
+		row     = structMapRow{
+			structMapBase: structMapBase{ID: 42},
+			Name:          "name",
+		}
+		fields     = getStructFields(reflect.TypeOf(row))
+		indexes, _ = fields.mapping([]string{"id", "name", "comment"})
+	)
+	value, err := structValue(&row)
+	if assert.NoError(t, err) {
+		assert.Equal(t, uint64(42), fieldValue(value, indexes[0]))
+		assert.Equal(t, "name", fieldValue(value, indexes[1]))
+		assert.Nil(t, fieldValue(value, indexes[2]), "nil embedded struct")
+	}
+	if ptr, ok := fieldAddr(value, indexes[2]).(**string); assert.True(t, ok) {
+		*ptr = &comment
+		assert.Equal(t, &comment, row.Comment)
+	}
+	for _, v := range []interface{}{nil, 42, "struct", (*structMapRow)(nil)} {
+		_, err := structValue(v)
+		assert.Equal(t, ErrStructExpected, err)
+	}
+}
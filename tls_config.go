@@ -1,8 +1,20 @@
 package clickhouse
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 )
 
 // Based on the original implementation in the project go-sql-driver/mysql:
@@ -42,3 +54,160 @@ func getTLSConfigClone(key string) (config *tls.Config) {
 	tlsConfigLock.RUnlock()
 	return
 }
+
+// DefaultTLSReloadInterval is how often certificate files from the DSN are checked for changes
+const DefaultTLSReloadInterval = time.Minute
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+var (
+	tlsFilesLock  sync.Mutex
+	tlsFilesCache = make(map[tlsFiles]*tlsFilesEntry)
+)
+
+// tlsFiles is a set of certificate files given in the DSN.
+type tlsFiles struct {
+	ca, cert, key string
+}
+
+type tlsFilesEntry struct {
+	checked  time.Time
+	modified time.Time
+	rootCAs  *x509.CertPool
+	cert     *tls.Certificate
+}
+
+// makeTLSConfig applies the tls_* DSN parameters to config (which may be nil).
+// It returns nil if config is nil and no parameters are set.
+func makeTLSConfig(config *tls.Config, query url.Values) (*tls.Config, error) {
+	var (
+		files = tlsFiles{
+			ca:   query.Get("tls_ca_file"),
+			cert: query.Get("tls_cert_file"),
+			key:  query.Get("tls_key_file"),
+		}
+		serverName  = query.Get("tls_server_name")
+		minVersion  = query.Get("tls_min_version")
+		fingerprint = query.Get("tls_fingerprint")
+		interval    = DefaultTLSReloadInterval
+	)
+	if config == nil {
+		if files == (tlsFiles{}) && serverName == "" && minVersion == "" && fingerprint == "" {
+			return nil, nil
+		}
+		config = &tls.Config{}
+	}
+	if (files.cert == "") != (files.key == "") {
+		return nil, errors.New("tls_cert_file and tls_key_file must be specified together")
+	}
+	if duration, err := strconv.ParseFloat(query.Get("tls_reload_interval"), 64); err == nil {
+		interval = time.Duration(duration * float64(time.Second))
+	}
+	if files != (tlsFiles{}) {
+		entry, err := loadTLSFiles(files, interval)
+		if err != nil {
+			return nil, err
+		}
+		if entry.rootCAs != nil {
+			config.RootCAs = entry.rootCAs
+		}
+		if entry.cert != nil {
+			config.Certificates = []tls.Certificate{*entry.cert}
+		}
+	}
+	if serverName != "" {
+		config.ServerName = serverName
+	}
+	if minVersion != "" {
+		version, ok := tlsVersions[minVersion]
+		if !ok {
+			return nil, fmt.Errorf("invalid tls_min_version %q", minVersion)
+		}
+		config.MinVersion = version
+	}
+	if fingerprint != "" {
+		pin, err := hex.DecodeString(strings.ReplaceAll(fingerprint, ":", ""))
+		if err != nil || len(pin) != sha256.Size {
+			return nil, fmt.Errorf("invalid tls_fingerprint %q: expected hex encoded SHA-256", fingerprint)
+		}
+		config.VerifyPeerCertificate = verifyFingerprint(pin)
+	}
+	return config, nil
+}
+
+// verifyFingerprint pins the server certificate to the SHA-256 fingerprint of its DER encoding.
+func verifyFingerprint(pin []byte) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errors.New("tls: server did not provide a certificate")
+		}
+		if sum := sha256.Sum256(rawCerts[0]); !bytes.Equal(sum[:], pin) {
+			return fmt.Errorf("tls: server certificate fingerprint %x does not match tls_fingerprint", sum)
+		}
+		return nil
+	}
+}
+
+// loadTLSFiles returns the certificates from files, they are read again
+// when the files have changed, but not more often than once per interval.
+func loadTLSFiles(files tlsFiles, interval time.Duration) (*tlsFilesEntry, error) {
+	tlsFilesLock.Lock()
+	defer tlsFilesLock.Unlock()
+	entry, found := tlsFilesCache[files]
+	if found && time.Since(entry.checked) < interval {
+		return entry, nil
+	}
+	modified, err := files.modified()
+	if err != nil {
+		return nil, err
+	}
+	if found && !modified.After(entry.modified) {
+		entry.checked = time.Now()
+		return entry, nil
+	}
+	loaded := tlsFilesEntry{
+		checked:  time.Now(),
+		modified: modified,
+	}
+	if files.ca != "" {
+		pem, err := ioutil.ReadFile(files.ca)
+		if err != nil {
+			return nil, err
+		}
+		loaded.rootCAs = x509.NewCertPool()
+		if !loaded.rootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls_ca_file %s: no certificates found", files.ca)
+		}
+	}
+	if files.cert != "" {
+		cert, err := tls.LoadX509KeyPair(files.cert, files.key)
+		if err != nil {
+			return nil, err
+		}
+		loaded.cert = &cert
+	}
+	tlsFilesCache[files] = &loaded
+	return &loaded, nil
+}
+
+func (files tlsFiles) modified() (time.Time, error) {
+	var modified time.Time
+	for _, name := range []string{files.ca, files.cert, files.key} {
+		if name == "" {
+			continue
+		}
+		info, err := os.Stat(name)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(modified) {
+			modified = info.ModTime()
+		}
+	}
+	return modified, nil
+}
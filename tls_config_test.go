@@ -0,0 +1,112 @@
+package clickhouse
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_MakeTLSConfig(t *testing.T) {
+	config, err := makeTLSConfig(nil, url.Values{})
+	if assert.NoError(t, err) {
+		assert.Nil(t, config)
+	}
+	config, err = makeTLSConfig(nil, url.Values{
+		"tls_server_name": {"clickhouse.local"},
+		"tls_min_version": {"1.2"},
+	})
+	if assert.NoError(t, err) && assert.NotNil(t, config) {
+		assert.Equal(t, "clickhouse.local", config.ServerName)
+		assert.Equal(t, uint16(tls.VersionTLS12), config.MinVersion)
+	}
+	for _, query := range []url.Values{
+		{"tls_min_version": {"2.0"}},
+		{"tls_fingerprint": {"abc"}},
+		{"tls_cert_file": {"client.crt"}},
+		{"tls_ca_file": {"not_exists.crt"}},
+	} {
+		_, err := makeTLSConfig(nil, query)
+		assert.Error(t, err, query.Encode())
+	}
+}
+
+func Test_MakeTLSConfigFiles(t *testing.T) {
+	cert, err := selfSignedCertificate()
+	if !assert.NoError(t, err) {
+		return
+	}
+	dir, err := ioutil.TempDir("", "clickhouse_tls")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	var (
+		certFile = filepath.Join(dir, "client.crt")
+		keyFile  = filepath.Join(dir, "client.key")
+		certPEM  = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]})
+		keyPEM   = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key})
+	)
+	assert.NoError(t, ioutil.WriteFile(certFile, certPEM, 0600))
+	assert.NoError(t, ioutil.WriteFile(keyFile, keyPEM, 0600))
+	query := url.Values{
+		"tls_ca_file":         {certFile},
+		"tls_cert_file":       {certFile},
+		"tls_key_file":        {keyFile},
+		"tls_reload_interval": {"0"},
+	}
+	config, err := makeTLSConfig(nil, query)
+	if assert.NoError(t, err) && assert.NotNil(t, config) {
+		assert.NotNil(t, config.RootCAs)
+		if assert.Len(t, config.Certificates, 1) {
+			assert.Equal(t, cert.Certificate[0], config.Certificates[0].Certificate[0])
+		}
+	}
+
+	// rotate the client certificate
+	rotated, err := selfSignedCertificate()
+	if !assert.NoError(t, err) {
+		return
+	}
+	if key, err = x509.MarshalPKCS8PrivateKey(rotated.PrivateKey); !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: rotated.Certificate[0]}), 0600))
+	assert.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}), 0600))
+	future := time.Now().Add(time.Minute)
+	assert.NoError(t, os.Chtimes(certFile, future, future))
+	config, err = makeTLSConfig(nil, query)
+	if assert.NoError(t, err) && assert.Len(t, config.Certificates, 1) {
+		assert.Equal(t, rotated.Certificate[0], config.Certificates[0].Certificate[0])
+	}
+}
+
+func Test_VerifyFingerprint(t *testing.T) {
+	cert, err := selfSignedCertificate()
+	if !assert.NoError(t, err) {
+		return
+	}
+	sum := sha256.Sum256(cert.Certificate[0])
+	config, err := makeTLSConfig(nil, url.Values{"tls_fingerprint": {hex.EncodeToString(sum[:])}})
+	if assert.NoError(t, err) && assert.NotNil(t, config.VerifyPeerCertificate) {
+		assert.NoError(t, config.VerifyPeerCertificate(cert.Certificate, nil))
+		other, err := selfSignedCertificate()
+		if assert.NoError(t, err) {
+			assert.Error(t, config.VerifyPeerCertificate(other.Certificate, nil))
+		}
+		assert.Error(t, config.VerifyPeerCertificate(nil, nil))
+	}
+}
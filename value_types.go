@@ -0,0 +1,431 @@
+package clickhouse
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"time"
+)
+
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+// ArrayOf is an Array value with elements of type T, which can be a user type implementing
+// sql.Scanner and driver.Valuer or a type convertible to and from the type of the elements:
+//
+//	var ids clickhouse.ArrayOf[UserID]
+//	err := db.QueryRow("SELECT [1, 2, 3]").Scan(&ids)
+//
+// The value is bound into queries as the comma separated elements, e.g. "[?]" or "IN (?)".
+type ArrayOf[T any] []T
+
+// Scan implements sql.Scanner, converting the elements as database/sql does.
+// NULL elements of Array(Nullable(T)) are scanned into pointers, Scanners or interfaces.
+func (array *ArrayOf[T]) Scan(src interface{}) error {
+	if src == nil {
+		*array = nil
+		return nil
+	}
+	value := reflect.ValueOf(src)
+	if value.Kind() != reflect.Slice {
+		return fmt.Errorf("clickhouse: cannot scan %T into %T", src, array)
+	}
+	values := make(ArrayOf[T], value.Len())
+	for i := range values {
+		if err := assignValue(reflect.ValueOf(&values[i]).Elem(), value.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	*array = values
+	return nil
+}
+
+// Value implements driver.Valuer, converting the elements with their Value method or into their underlying types.
+func (array ArrayOf[T]) Value() (driver.Value, error) {
+	return driverValue([]T(array))
+}
+
+// TupleValue is a Tuple value, its elements are scanned as they are returned by the driver:
+//
+//	var tuple clickhouse.TupleValue
+//	if err := db.QueryRow("SELECT tuple(1, 'a')").Scan(&tuple); err != nil {
+//		return err
+//	}
+//	var (
+//		id   UserID
+//		name string
+//	)
+//	err := tuple.Unpack(&id, &name)
+//
+// The value is bound into queries as the comma separated elements, e.g. "tuple(?)".
+type TupleValue []interface{}
+
+// Scan implements sql.Scanner.
+func (tuple *TupleValue) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		*tuple = nil
+	case []interface{}:
+		*tuple = append(TupleValue(nil), src...)
+	default:
+		return fmt.Errorf("clickhouse: cannot scan %T into %T", src, tuple)
+	}
+	return nil
+}
+
+// Value implements driver.Valuer, converting the elements with their Value method or into their underlying types.
+func (tuple TupleValue) Value() (driver.Value, error) {
+	values := make([]interface{}, len(tuple))
+	for i, v := range tuple {
+		value, err := driverValue(v)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// Unpack assigns the elements of the tuple to the values pointed to by dest,
+// converting them as ArrayOf does.
+func (tuple TupleValue) Unpack(dest ...interface{}) error {
+	if len(dest) != len(tuple) {
+		return fmt.Errorf("clickhouse: expected %d destinations for the tuple, got %d", len(tuple), len(dest))
+	}
+	for i, d := range dest {
+		value := reflect.ValueOf(d)
+		if value.Kind() != reflect.Ptr || value.IsNil() {
+			return fmt.Errorf("clickhouse: destination %d of the tuple is not a pointer", i)
+		}
+		if err := assignValue(value.Elem(), tuple[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MapValue is a map value. It is bound into queries as its keys and values in the order
+// of the keys, the arguments of the map function: "map(?)".
+// It is scanned from an Array(Tuple(K, V)) of the pairs, e.g. "arrayZip(mapKeys(m), mapValues(m))",
+// converting the keys and values as ArrayOf does.
+type MapValue[K comparable, V any] map[K]V
+
+// Scan implements sql.Scanner.
+func (m *MapValue[K, V]) Scan(src interface{}) error {
+	if src == nil {
+		*m = nil
+		return nil
+	}
+	value := reflect.ValueOf(src)
+	if value.Kind() != reflect.Slice {
+		return fmt.Errorf("clickhouse: cannot scan %T into %T", src, m)
+	}
+	values := make(MapValue[K, V], value.Len())
+	for i := 0; i < value.Len(); i++ {
+		pair, ok := value.Index(i).Interface().([]interface{})
+		if !ok || len(pair) != 2 {
+			return fmt.Errorf("clickhouse: cannot scan %T into %T, expected key-value tuples", src, m)
+		}
+		var (
+			key K
+			val V
+		)
+		if err := assignValue(reflect.ValueOf(&key).Elem(), pair[0]); err != nil {
+			return err
+		}
+		if err := assignValue(reflect.ValueOf(&val).Elem(), pair[1]); err != nil {
+			return err
+		}
+		values[key] = val
+	}
+	*m = values
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (m MapValue[K, V]) Value() (driver.Value, error) {
+	return mapArgs(reflect.ValueOf(map[K]V(m)))
+}
+
+// mapArgs returns the keys and values of the map in the order of the quoted keys.
+func mapArgs(m reflect.Value) ([]interface{}, error) {
+	type entry struct {
+		quoted     string
+		key, value interface{}
+	}
+	entries := make([]entry, 0, m.Len())
+	for iter := m.MapRange(); iter.Next(); {
+		key, err := driverValue(iter.Key().Interface())
+		if err != nil {
+			return nil, err
+		}
+		value, err := driverValue(iter.Value().Interface())
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry{quoted: quote(key), key: key, value: value})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].quoted < entries[j].quoted
+	})
+	args := make([]interface{}, 0, 2*len(entries))
+	for _, entry := range entries {
+		args = append(args, entry.key, entry.value)
+	}
+	return args, nil
+}
+
+// driverValue converts v into a value the columns can write and the queries can bind:
+// driver.Valuers are replaced by their values, named types by their underlying types
+// and slices are converted element by element.
+func driverValue(v interface{}) (driver.Value, error) {
+	switch v.(type) {
+	case nil, []byte, int8, int16, int32, int64, uint8, uint16, uint32, uint64, float32, float64, string, time.Time, net.IP:
+		return v, nil
+	}
+	value := reflect.ValueOf(v)
+	if value.Kind() == reflect.Ptr && value.IsNil() {
+		return nil, nil
+	}
+	if valuer, ok := v.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := v.(driver.Valuer); ok {
+			return v, nil
+		}
+		return driverValue(v)
+	}
+	switch value.Kind() {
+	case reflect.Ptr:
+		return driverValue(value.Elem().Interface())
+	case reflect.Slice:
+		return driverSlice(value)
+	case reflect.Map:
+		return mapArgs(value)
+	}
+	if v, ok := basicValue(value); ok {
+		return v, nil
+	}
+	return v, nil
+}
+
+// driverSlice converts the elements of the slice, keeping the slice if they need no conversion.
+// The converted elements are returned in a typed slice, of pointers if some are nil,
+// or in a []interface{} if their types differ.
+func driverSlice(slice reflect.Value) (driver.Value, error) {
+	elemType := slice.Type().Elem()
+	if isDriverType(elemType) {
+		if slice.Type().PkgPath() == "" {
+			return slice.Interface(), nil
+		}
+		return slice.Convert(reflect.SliceOf(elemType)).Interface(), nil
+	}
+	var (
+		typ      reflect.Type
+		mixed    bool
+		nullable = elemType.Kind() == reflect.Ptr
+		values   = make([]interface{}, slice.Len())
+	)
+	for i := range values {
+		value, err := driverValue(slice.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		switch values[i] = value; {
+		case value == nil:
+			nullable = true
+		case typ == nil:
+			typ = reflect.TypeOf(value)
+		case typ != reflect.TypeOf(value):
+			mixed = true
+		}
+	}
+	if typ == nil {
+		// the type of the converted zero element, for empty slices and slices of NULLs
+		zero := elemType
+		for zero.Kind() == reflect.Ptr {
+			zero = zero.Elem()
+		}
+		value, err := driverValue(reflect.Zero(zero).Interface())
+		if err != nil || value == nil {
+			return values, nil
+		}
+		typ = reflect.TypeOf(value)
+	}
+	if mixed || elemType.Kind() == reflect.Interface {
+		return values, nil
+	}
+	if nullable {
+		typ = reflect.PtrTo(typ)
+	}
+	converted := reflect.MakeSlice(reflect.SliceOf(typ), len(values), len(values))
+	for i, value := range values {
+		switch {
+		case value == nil:
+		case nullable:
+			ptr := reflect.New(typ.Elem())
+			ptr.Elem().Set(reflect.ValueOf(value))
+			converted.Index(i).Set(ptr)
+		default:
+			converted.Index(i).Set(reflect.ValueOf(value))
+		}
+	}
+	return converted.Interface(), nil
+}
+
+// isDriverType reports whether the values of the type need no conversion.
+func isDriverType(t reflect.Type) bool {
+	if t.PkgPath() != "" || t.Implements(valuerType) {
+		return t == timeType
+	}
+	switch t.Kind() {
+	case
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return true
+	case reflect.Slice:
+		return isDriverType(t.Elem())
+	}
+	return false
+}
+
+// basicValue converts the value of a named type into its underlying type.
+func basicValue(value reflect.Value) (interface{}, bool) {
+	switch value.Kind() {
+	case reflect.Bool:
+		if value.Bool() {
+			return uint8(1), true
+		}
+		return uint8(0), true
+	case reflect.Int8:
+		return int8(value.Int()), true
+	case reflect.Int16:
+		return int16(value.Int()), true
+	case reflect.Int32:
+		return int32(value.Int()), true
+	case reflect.Int, reflect.Int64:
+		return value.Int(), true
+	case reflect.Uint8:
+		return uint8(value.Uint()), true
+	case reflect.Uint16:
+		return uint16(value.Uint()), true
+	case reflect.Uint32:
+		return uint32(value.Uint()), true
+	case reflect.Uint, reflect.Uint64:
+		return value.Uint(), true
+	case reflect.Float32:
+		return float32(value.Float()), true
+	case reflect.Float64:
+		return float64(value.Float()), true
+	case reflect.String:
+		return value.String(), true
+	}
+	return nil, false
+}
+
+// assignValue assigns src, a value as it is returned by the driver, to dest. Scanners scan the value,
+// numbers and strings are converted into the types of the same kind and slices element by element.
+func assignValue(dest reflect.Value, src interface{}) error {
+	if scanner, ok := dest.Addr().Interface().(sql.Scanner); ok {
+		return scanner.Scan(src)
+	}
+	value := reflect.ValueOf(src)
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	if !value.IsValid() || value.Kind() == reflect.Ptr {
+		switch dest.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+			dest.Set(reflect.Zero(dest.Type()))
+			return nil
+		}
+		return fmt.Errorf("clickhouse: cannot scan NULL into %s", dest.Type())
+	}
+	switch {
+	case value.Type().AssignableTo(dest.Type()):
+		dest.Set(value)
+		return nil
+	case dest.Kind() == reflect.Ptr:
+		elem := reflect.New(dest.Type().Elem())
+		if err := assignValue(elem.Elem(), value.Interface()); err != nil {
+			return err
+		}
+		dest.Set(elem)
+		return nil
+	case dest.Kind() == reflect.Slice && value.Kind() == reflect.Slice:
+		slice := reflect.MakeSlice(dest.Type(), value.Len(), value.Len())
+		for i := 0; i < value.Len(); i++ {
+			if err := assignValue(slice.Index(i), value.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		dest.Set(slice)
+		return nil
+	case value.Kind() == reflect.String && dest.Kind() == reflect.String:
+		dest.SetString(value.String())
+		return nil
+	}
+	if convertNumber(dest, value) {
+		return nil
+	}
+	return fmt.Errorf("clickhouse: cannot scan %s into %s", value.Type(), dest.Type())
+}
+
+// convertNumber sets dest to the number if it fits into the type of dest.
+func convertNumber(dest, value reflect.Value) bool {
+	switch dest.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch value.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n = value.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if value.Uint() > 1<<63-1 {
+				return false
+			}
+			n = int64(value.Uint())
+		default:
+			return false
+		}
+		if dest.OverflowInt(n) {
+			return false
+		}
+		dest.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		switch value.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if value.Int() < 0 {
+				return false
+			}
+			n = uint64(value.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n = value.Uint()
+		default:
+			return false
+		}
+		if dest.OverflowUint(n) {
+			return false
+		}
+		dest.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		switch value.Kind() {
+		case reflect.Float32, reflect.Float64:
+			dest.SetFloat(value.Float())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			dest.SetFloat(float64(value.Int()))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			dest.SetFloat(float64(value.Uint()))
+		default:
+			return false
+		}
+	default:
+		return false
+	}
+	return true
+}
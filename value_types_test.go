@@ -0,0 +1,175 @@
+package clickhouse
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// userID is a user type implementing sql.Scanner and driver.Valuer
+type userID struct {
+	id uint64
+}
+
+func (u *userID) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case uint64:
+		u.id = src
+	case uint8:
+		u.id = uint64(src)
+	default:
+		return fmt.Errorf("cannot scan %T into userID", src)
+	}
+	return nil
+}
+
+func (u userID) Value() (driver.Value, error) {
+	return u.id, nil
+}
+
+type score float32
+
+type (
+	rank int
+	size uint
+)
+
+func Test_ArrayOfScan(t *testing.T) {
+	var ids ArrayOf[userID]
+	if assert.NoError(t, ids.Scan([]uint64{1, 2})) {
+		assert.Equal(t, ArrayOf[userID]{{1}, {2}}, ids)
+	}
+	var scores ArrayOf[score]
+	if assert.NoError(t, scores.Scan([]float32{1.5})) {
+		assert.Equal(t, ArrayOf[score]{1.5}, scores)
+	}
+	var widened ArrayOf[int64]
+	if assert.NoError(t, widened.Scan([]int8{-1, 2})) {
+		assert.Equal(t, ArrayOf[int64]{-1, 2}, widened)
+	}
+	assert.Error(t, new(ArrayOf[uint8]).Scan([]int64{256}))
+	assert.Error(t, new(ArrayOf[uint64]).Scan([]int64{-1}))
+	assert.Error(t, new(ArrayOf[string]).Scan([]int64{1}))
+
+	one := int32(1)
+	var nullable ArrayOf[*int64]
+	if assert.NoError(t, nullable.Scan([]*int32{&one, nil})) && assert.Len(t, nullable, 2) {
+		assert.Equal(t, int64(1), *nullable[0])
+		assert.Nil(t, nullable[1])
+	}
+	assert.Error(t, new(ArrayOf[int64]).Scan([]*int32{nil}))
+
+	var nested ArrayOf[[]score]
+	if assert.NoError(t, nested.Scan([][]float32{{1}, {}, {2, 3}})) {
+		assert.Equal(t, ArrayOf[[]score]{{1}, {}, {2, 3}}, nested)
+	}
+	var tuples ArrayOf[TupleValue]
+	if assert.NoError(t, tuples.Scan([][]interface{}{{uint8(1), "a"}})) {
+		assert.Equal(t, ArrayOf[TupleValue]{{uint8(1), "a"}}, tuples)
+	}
+	if assert.NoError(t, ids.Scan(nil)) {
+		assert.Nil(t, ids)
+	}
+	assert.Error(t, ids.Scan("1"))
+}
+
+func Test_ArrayOfValue(t *testing.T) {
+	two := score(2)
+	for _, test := range []struct {
+		value    driver.Valuer
+		expected driver.Value
+	}{
+		{ArrayOf[int32]{1, 2}, []int32{1, 2}},
+		{ArrayOf[userID]{{1}, {2}}, []uint64{1, 2}},
+		{ArrayOf[userID]{}, []uint64{}},
+		{ArrayOf[score]{1.5}, []float32{1.5}},
+		{ArrayOf[rank]{1, 2}, []int64{1, 2}},
+		{ArrayOf[size]{3}, []uint64{3}},
+		{ArrayOf[*score]{&two, nil}, []*float32{ptrFloat32(2), nil}},
+		{ArrayOf[[]score]{{1}, {2, 3}}, [][]float32{{1}, {2, 3}}},
+		{ArrayOf[[]string]{{"a"}}, [][]string{{"a"}}},
+		{ArrayOf[interface{}]{1, "a"}, []interface{}{int64(1), "a"}},
+	} {
+		value, err := test.value.Value()
+		if assert.NoError(t, err) {
+			assert.Equal(t, test.expected, value, "%T", test.value)
+		}
+	}
+}
+
+func ptrFloat32(v float32) *float32 {
+	return &v
+}
+
+func Test_TupleValue(t *testing.T) {
+	var tuple TupleValue
+	if !assert.NoError(t, tuple.Scan([]interface{}{uint64(42), "name", []uint8{1, 2}, nil})) {
+		return
+	}
+	var (
+		id      userID
+		name    string
+		codes   []int
+		comment *string
+	)
+	if assert.NoError(t, tuple.Unpack(&id, &name, &codes, &comment)) {
+		assert.Equal(t, userID{42}, id)
+		assert.Equal(t, "name", name)
+		assert.Equal(t, []int{1, 2}, codes)
+		assert.Nil(t, comment)
+	}
+	assert.Error(t, tuple.Unpack(&id, &name))
+	assert.Error(t, tuple.Unpack(id, &name, &codes, &comment))
+	if value, err := (TupleValue{userID{1}, score(1.5), "a"}).Value(); assert.NoError(t, err) {
+		assert.Equal(t, []interface{}{uint64(1), float32(1.5), "a"}, value)
+		assert.Equal(t, "1, 1.5, 'a'", quote(value))
+	}
+	assert.Error(t, tuple.Scan([]string{"a"}))
+}
+
+func Test_MapValue(t *testing.T) {
+	var m MapValue[string, userID]
+	if assert.NoError(t, m.Scan([][]interface{}{{"a", uint64(1)}, {"b", uint64(2)}})) {
+		assert.Equal(t, MapValue[string, userID]{"a": {1}, "b": {2}}, m)
+	}
+	assert.Error(t, m.Scan([]string{"a"}))
+	assert.Error(t, m.Scan([][]interface{}{{"a"}}))
+	if value, err := (MapValue[string, userID]{"b": {2}, "a": {1}}).Value(); assert.NoError(t, err) {
+		assert.Equal(t, []interface{}{"a", uint64(1), "b", uint64(2)}, value)
+		assert.Equal(t, "'a', 1, 'b', 2", quote(value))
+	}
+}
+
+func Test_CheckNamedValueConversions(t *testing.T) {
+	var (
+		ch  = &clickhouse{}
+		one = score(1)
+	)
+	for _, test := range []struct {
+		value    interface{}
+		expected interface{}
+	}{
+		{score(1.5), float32(1.5)},
+		{rank(-3), int64(-3)},
+		{size(3), uint64(3)},
+		{[]rank{1, 2}, []int64{1, 2}},
+		{true, uint8(1)},
+		{[]string{"a"}, []string{"a"}},
+		{[]userID{{1}, {2}}, []uint64{1, 2}},
+		{[]score{1}, []float32{1}},
+		{[]*score{&one, nil}, []*float32{ptrFloat32(1), nil}},
+		{ArrayOf[userID]{{3}}, []uint64{3}},
+		{TupleValue{userID{1}, "a"}, []interface{}{uint64(1), "a"}},
+		{map[string]score{"x": 1}, []interface{}{"x", float32(1)}},
+		{userID{7}, uint64(7)},
+		{Date(time.Date(2022, 1, 2, 3, 4, 5, 0, time.Local)), time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC)},
+	} {
+		nv := driver.NamedValue{Value: test.value}
+		if assert.NoError(t, ch.CheckNamedValue(&nv), "%T", test.value) {
+			assert.Equal(t, test.expected, nv.Value, "%T", test.value)
+		}
+	}
+}
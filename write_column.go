@@ -1,8 +1,10 @@
 package clickhouse
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
+	"io"
 	"time"
 
 	"github.com/ClickHouse/clickhouse-go/lib/data"
@@ -17,6 +19,13 @@ type Clickhouse interface {
 	Rollback() error
 	Close() error
 	WriteBlock(block *data.Block) error
+	PrepareBatch(ctx context.Context, query string) (Batch, error)
+	PrepareStructBatch(ctx context.Context, table string, v interface{}) (Batch, error)
+	AsyncInsert(ctx context.Context, query string, args ...interface{}) error
+	AsyncInsertNoWait(ctx context.Context, query string, args ...interface{}) error
+	InsertFromReader(ctx context.Context, table string, format InputFormat, r io.Reader) error
+	QueryBlocks(ctx context.Context, query string, args ...interface{}) (*Blocks, error)
+	ExecScript(ctx context.Context, script string) ([]ScriptResult, error)
 }
 
 // Interface for Block allowing writes to individual columns
@@ -45,6 +54,7 @@ type ColumnWriter interface {
 	WriteStringNullable(c int, v *string) error
 	WriteFixedString(c int, v []byte) error
 	WriteFixedStringNullable(c int, v *[]byte) error
+	AppendColumn(c int, v interface{}) error
 }
 
 func OpenDirect(dsn string) (Clickhouse, error) {